@@ -0,0 +1,188 @@
+package applicant
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/lego"
+	jose "github.com/go-jose/go-jose/v4"
+
+	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
+)
+
+// 解析 ACME 账户私钥。
+// 支持 PEM 格式，以及 certbot 的 `accounts/` 目录中 `private_key.json` 所使用的 JWK 格式。
+func parseAcmeAccountKey(data string) (crypto.Signer, error) {
+	data = strings.TrimSpace(data)
+
+	var privkey crypto.PrivateKey
+	if strings.HasPrefix(data, "{") {
+		jwk := jose.JSONWebKey{}
+		if err := jwk.UnmarshalJSON([]byte(data)); err != nil {
+			return nil, fmt.Errorf("failed to parse jwk: %w", err)
+		}
+		if jwk.IsPublic() {
+			return nil, errors.New("the jwk is not a private key")
+		}
+		privkey = jwk.Key
+	} else {
+		key, err := xcert.ParsePrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		privkey = key
+	}
+
+	switch key := privkey.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	}
+
+	return nil, errors.New("unsupported private key type, only RSA and ECDSA are allowed")
+}
+
+func getAcmeAccountKeyAlgorithm(key crypto.Signer) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jose.RS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+	}
+
+	return "", errors.New("unsupported private key type")
+}
+
+// 更换 ACME 账户的密钥。
+// 参考 RFC 8555 §7.3.5：内层 JWS 由新密钥签名，外层 JWS 由旧密钥以账户身份签名。
+func rolloverAcmeAccountKey(config *lego.Config, accountURL string, oldKey crypto.Signer, newKey crypto.Signer) error {
+	directory := acme.Directory{}
+	if err := doAcmeRequest(config.HTTPClient, http.MethodGet, config.CADirURL, nil, &directory, nil); err != nil {
+		return fmt.Errorf("failed to get acme directory: %w", err)
+	}
+	if directory.KeyChangeURL == "" {
+		return errors.New("the acme server does not support key rollover")
+	}
+
+	// 内层 JWS
+	newAlg, err := getAcmeAccountKeyAlgorithm(newKey)
+	if err != nil {
+		return err
+	}
+	innerSigner, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: newAlg, Key: newKey},
+		(&jose.SignerOptions{EmbedJWK: true}).WithHeader("url", directory.KeyChangeURL),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create inner jws signer: %w", err)
+	}
+	innerPayload, err := json.Marshal(map[string]any{
+		"account": accountURL,
+		"oldKey":  jose.JSONWebKey{Key: oldKey.Public()},
+	})
+	if err != nil {
+		return err
+	}
+	innerJWS, err := innerSigner.Sign(innerPayload)
+	if err != nil {
+		return fmt.Errorf("failed to sign inner jws: %w", err)
+	}
+
+	// 外层 JWS
+	nonceHeader := make(http.Header)
+	if err := doAcmeRequest(config.HTTPClient, http.MethodHead, directory.NewNonceURL, nil, nil, nonceHeader); err != nil {
+		return fmt.Errorf("failed to get acme nonce: %w", err)
+	}
+	nonce := nonceHeader.Get("Replay-Nonce")
+	if nonce == "" {
+		return errors.New("failed to get acme nonce: empty response")
+	}
+
+	oldAlg, err := getAcmeAccountKeyAlgorithm(oldKey)
+	if err != nil {
+		return err
+	}
+	outerSigner, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: oldAlg, Key: jose.JSONWebKey{Key: oldKey, KeyID: accountURL}},
+		(&jose.SignerOptions{}).WithHeader("url", directory.KeyChangeURL).WithHeader("nonce", nonce),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create outer jws signer: %w", err)
+	}
+	outerJWS, err := outerSigner.Sign([]byte(innerJWS.FullSerialize()))
+	if err != nil {
+		return fmt.Errorf("failed to sign outer jws: %w", err)
+	}
+
+	if err := doAcmeRequest(config.HTTPClient, http.MethodPost, directory.KeyChangeURL, []byte(outerJWS.FullSerialize()), nil, nil); err != nil {
+		return fmt.Errorf("failed to change acme account key: %w", err)
+	}
+
+	return nil
+}
+
+func doAcmeRequest(client *http.Client, method string, url string, body []byte, result any, respHeader http.Header) error {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = strings.NewReader(string(body))
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/jose+json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		problem := acme.ProblemDetails{}
+		if err := json.Unmarshal(respBody, &problem); err == nil && problem.Type != "" {
+			problem.HTTPStatus = resp.StatusCode
+			return &problem
+		}
+		return fmt.Errorf("unexpected status code: %d, resp: %s", resp.StatusCode, string(respBody))
+	}
+
+	for key, values := range resp.Header {
+		if respHeader != nil {
+			respHeader[key] = values
+		}
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,17 @@
 package applicant
 
-import "github.com/certimate-go/certimate/internal/domain"
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-acme/lego/v4/lego"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
+)
 
 const (
 	caLetsEncrypt         = string(domain.CAProviderTypeLetsEncrypt)
@@ -29,3 +40,61 @@ type acmeSSLProviderConfig struct {
 	Config   map[domain.CAProviderType]map[string]any `json:"config"`
 	Provider string                                   `json:"provider"`
 }
+
+// 为自定义 ACME CA 配置 HTTP 客户端的 TLS 信任设置。
+// 自定义的根证书将追加到系统信任库中；如果配置了客户端证书，则在与 ACME 服务端通信时进行双向 TLS 认证。
+func configureCustomCAHTTPClient(config *lego.Config, caAccessConfig map[string]any) error {
+	caCertPEM := xmaps.GetString(caAccessConfig, "caCertificate")
+	clientCertPEM := xmaps.GetString(caAccessConfig, "clientCertificate")
+	clientPrivkeyPEM := xmaps.GetString(caAccessConfig, "clientPrivateKey")
+	if caCertPEM == "" && clientCertPEM == "" {
+		return nil
+	}
+
+	transport, ok := config.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("unsupported http transport of acme client")
+	}
+
+	transport = transport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+
+	if caCertPEM != "" {
+		pool := transport.TLSClientConfig.RootCAs
+		if pool == nil {
+			if systemPool, err := x509.SystemCertPool(); err == nil {
+				pool = systemPool
+			} else {
+				pool = x509.NewCertPool()
+			}
+		} else {
+			pool = pool.Clone()
+		}
+
+		if !pool.AppendCertsFromPEM([]byte(caCertPEM)) {
+			return errors.New("invalid ca certificate of acme server")
+		}
+
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if clientCertPEM != "" {
+		if clientPrivkeyPEM == "" {
+			return errors.New("the private key of client certificate is not configured")
+		}
+
+		clientCert, err := tls.X509KeyPair([]byte(clientCertPEM), []byte(clientPrivkeyPEM))
+		if err != nil {
+			return fmt.Errorf("invalid client certificate: %w", err)
+		}
+
+		transport.TLSClientConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	httpClient := *config.HTTPClient
+	httpClient.Transport = transport
+	config.HTTPClient = &httpClient
+	return nil
+}
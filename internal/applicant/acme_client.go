@@ -0,0 +1,155 @@
+package applicant
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/registration"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/repository"
+	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
+)
+
+// 使用签发证书时的 ACME 账户创建 ACME 客户端。
+func newAcmeClientWithCertificateAccount(ctx context.Context, cert *domain.Certificate) (*lego.Client, error) {
+	if cert.ACMEAccountUrl == "" {
+		return nil, errors.New("certificate is not issued via acme")
+	}
+
+	acmeAccount, err := repository.NewAcmeAccountRepository().GetByUrl(ctx, cert.ACMEAccountUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get acme account: %w", err)
+	}
+
+	user := &acmeUser{
+		CA:           acmeAccount.CA,
+		Email:        acmeAccount.Email,
+		Registration: acmeAccount.Resource,
+		privkey:      acmeAccount.Key,
+	}
+
+	config, err := newAcmeConfigWithUser(ctx, user, string(cert.KeyAlgorithm))
+	if err != nil {
+		return nil, err
+	}
+
+	return lego.NewClient(config)
+}
+
+// 使用指定的 ACME 账户创建 ACME 客户端配置。
+func newAcmeConfigWithUser(ctx context.Context, user *acmeUser, keyAlgorithm string) (*lego.Config, error) {
+	caAccessConfig := make(map[string]any)
+	if user.getCAProvider() == caCustom {
+		// 自定义 ACME CA 的标识形如 "custom#{access_id}"
+		if _, caAccessId, ok := strings.Cut(user.CA, "#"); ok {
+			access, err := repository.NewAccessRepository().GetById(ctx, caAccessId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get access #%s record: %w", caAccessId, err)
+			}
+			caAccessConfig = access.Config
+		}
+	}
+
+	config := lego.NewConfig(user)
+	if caDirURL, err := getCADirURL(user, keyAlgorithm, caAccessConfig); err != nil {
+		return nil, err
+	} else {
+		config.CADirURL = caDirURL
+	}
+	if user.getCAProvider() == caCustom {
+		if err := configureCustomCAHTTPClient(config, caAccessConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// 使用证书自身的私钥创建 ACME 客户端。
+// 此时请求以内嵌 JWK 的方式签名，仅可用于吊销证书等无需 ACME 账户的操作。
+func newAcmeClientWithCertificateKey(ctx context.Context, cert *domain.Certificate) (*lego.Client, error) {
+	if cert.ACMEAccountUrl == "" {
+		return nil, errors.New("certificate is not issued via acme")
+	}
+	if cert.PrivateKey == "" {
+		return nil, errors.New("certificate has no private key")
+	}
+
+	privkey, err := certcrypto.ParsePEMPrivateKey([]byte(cert.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	// ACME 账户不存在时，只能根据账户地址推断 CA 目录地址
+	accountURL, err := url.Parse(cert.ACMEAccountUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid acme account url: %w", err)
+	}
+
+	config := lego.NewConfig(&certKeyUser{privkey: privkey})
+	if cert.CAProvider != caCustom {
+		for _, dirURL := range caDirUrls {
+			if u, err := url.Parse(dirURL); err == nil && u.Host == accountURL.Host {
+				config.CADirURL = dirURL
+				break
+			}
+		}
+	}
+	if config.CADirURL == "" {
+		// 自定义 ACME CA 的目录地址来自其授权信息
+		caAccess, err := findCustomCAAccessByHost(ctx, accountURL.Host)
+		if err != nil {
+			return nil, err
+		} else if caAccess == nil {
+			return nil, fmt.Errorf("could not determine acme directory for account '%s'", cert.ACMEAccountUrl)
+		}
+
+		config.CADirURL = xmaps.GetString(caAccess.Config, "endpoint")
+		if err := configureCustomCAHTTPClient(config, caAccess.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	return lego.NewClient(config)
+}
+
+// 查找目录地址与指定主机名一致的自定义 ACME CA 授权。
+func findCustomCAAccessByHost(ctx context.Context, host string) (*domain.Access, error) {
+	accesses, err := repository.NewAccessRepository().ListByProvider(ctx, string(domain.AccessProviderTypeACMECA))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom ca accesses: %w", err)
+	}
+
+	for _, access := range accesses {
+		if u, err := url.Parse(xmaps.GetString(access.Config, "endpoint")); err == nil && u.Host == host {
+			return access, nil
+		}
+	}
+
+	return nil, nil
+}
+
+type certKeyUser struct {
+	privkey crypto.PrivateKey
+}
+
+var _ registration.User = (*certKeyUser)(nil)
+
+func (u *certKeyUser) GetEmail() string {
+	return ""
+}
+
+func (u *certKeyUser) GetRegistration() *registration.Resource {
+	return nil
+}
+
+func (u *certKeyUser) GetPrivateKey() crypto.PrivateKey {
+	return u.privkey
+}
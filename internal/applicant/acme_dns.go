@@ -0,0 +1,126 @@
+package applicant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	acmednssdk "github.com/certimate-go/certimate/pkg/sdk3rd/acmedns"
+	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
+	xslices "github.com/certimate-go/certimate/pkg/utils/slices"
+)
+
+// 获取使用 acme-dns 时各域名所需的 CNAME 记录。
+// 当 [dtos.ApplicantGetAcmeDnsCNAMERecordsReq.Register] 为 true 时，为尚无账户的域名注册新账户，并将凭据保存到授权中。
+func (s *ApplicantService) GetAcmeDnsCNAMERecords(ctx context.Context, req *dtos.ApplicantGetAcmeDnsCNAMERecordsReq) (*dtos.ApplicantGetAcmeDnsCNAMERecordsResp, error) {
+	if req.AccessId == "" {
+		return nil, domain.ErrInvalidParams
+	}
+
+	domains := make([]string, 0)
+	for _, name := range strings.Split(req.Domains, ";") {
+		name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "*."))
+		if name != "" && !slices.Contains(domains, name) {
+			domains = append(domains, name)
+		}
+	}
+	if len(domains) == 0 {
+		return nil, domain.ErrInvalidParams
+	}
+
+	access, err := s.accessRepo.GetById(ctx, req.AccessId)
+	if err != nil {
+		return nil, err
+	}
+	if access.Provider != string(domain.AccessProviderTypeACMEDNS) {
+		return nil, fmt.Errorf("access #%s is not an acme-dns access", req.AccessId)
+	}
+
+	accessConfig := domain.AccessConfigForACMEDNS{}
+	if err := xmaps.Populate(access.Config, &accessConfig); err != nil {
+		return nil, fmt.Errorf("failed to populate access config: %w", err)
+	}
+
+	accounts := make(map[string]acmednssdk.Account)
+	if accessConfig.Credentials != "" {
+		if err := json.Unmarshal([]byte(accessConfig.Credentials), &accounts); err != nil {
+			return nil, fmt.Errorf("failed to parse acme-dns credentials: %w", err)
+		}
+	}
+
+	findAccount := func(name string) (acmednssdk.Account, bool) {
+		for key, account := range accounts {
+			if strings.ToLower(strings.TrimPrefix(key, "*.")) == name {
+				return account, true
+			}
+		}
+		return acmednssdk.Account{}, false
+	}
+
+	// 每注册一个账户即保存一次凭据，以免后续域名注册失败时已注册的账户丢失，其 CNAME 记录也将无从复现
+	saveAccounts := func() error {
+		credentials, err := json.Marshal(accounts)
+		if err != nil {
+			return err
+		}
+
+		access.Config["credentials"] = string(credentials)
+		if _, err := s.accessRepo.Save(context.WithoutCancel(ctx), access); err != nil {
+			return fmt.Errorf("failed to save acme-dns credentials: %w", err)
+		}
+
+		return nil
+	}
+
+	var client *acmednssdk.Client
+	records := make([]*dtos.ApplicantAcmeDnsCNAMERecord, 0, len(domains))
+	for _, name := range domains {
+		record := &dtos.ApplicantAcmeDnsCNAMERecord{
+			Domain: name,
+			Host:   fmt.Sprintf("_acme-challenge.%s", name),
+			Type:   "CNAME",
+		}
+
+		if account, ok := findAccount(name); ok {
+			record.Value = account.FullDomain
+			record.Registered = true
+		} else if req.Register {
+			if client == nil {
+				client, err = acmednssdk.NewClient(accessConfig.ServerUrl)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			allowFrom := xslices.Filter(req.AllowFrom, func(s string) bool { return s != "" })
+			resp, err := client.RegisterWithContext(ctx, &acmednssdk.RegisterRequest{AllowFrom: allowFrom})
+			if err != nil {
+				return nil, fmt.Errorf("failed to register acme-dns account for domain '%s': %w", name, err)
+			}
+
+			accounts[name] = resp.Account
+			if err := saveAccounts(); err != nil {
+				return nil, err
+			}
+
+			record.Value = resp.FullDomain
+			record.Registered = true
+		} else if accessConfig.Username != "" && accessConfig.FullDomain != "" {
+			// 未在凭据中单独配置的域名，均使用默认账户
+			// 默认账户的完整域名为可选项，未配置时无法得知 CNAME 的目标，视为未注册
+			record.Value = accessConfig.FullDomain
+			record.Registered = true
+		}
+
+		records = append(records, record)
+	}
+
+	return &dtos.ApplicantGetAcmeDnsCNAMERecordsResp{
+		Records: records,
+	}, nil
+}
@@ -75,7 +75,7 @@ func (u acmeUser) GetRegistration() *registration.Resource {
 }
 
 func (u *acmeUser) GetPrivateKey() crypto.PrivateKey {
-	rs, _ := xcert.ParseECPrivateKeyFromPEM(u.privkey)
+	rs, _ := xcert.ParsePrivateKeyFromPEM(u.privkey)
 	return rs
 }
 
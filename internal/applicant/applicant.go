@@ -2,11 +2,12 @@ package applicant
 
 import (
 	"context"
+	"crypto"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os"
-	"strconv"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/certimate-go/certimate/internal/repository"
+	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
 	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
 	xslices "github.com/certimate-go/certimate/pkg/utils/slices"
 )
@@ -34,6 +36,8 @@ type ApplyResult struct {
 	ACMECertUrl          string
 	ACMECertStableUrl    string
 	ARIReplaced          bool
+	PrivateKeyCreatedAt  time.Time
+	CAProvider           string
 }
 
 type Applicant interface {
@@ -43,6 +47,9 @@ type Applicant interface {
 type ApplicantWithWorkflowNodeConfig struct {
 	Node   *domain.WorkflowNode
 	Logger *slog.Logger
+	// 证书算法。
+	// 节点配置了多种算法时，每次仅申请其中一种；零值时使用节点配置的第一种算法。
+	KeyAlgorithm string
 }
 
 func NewWithWorkflowNode(config ApplicantWithWorkflowNodeConfig) (Applicant, error) {
@@ -54,22 +61,75 @@ func NewWithWorkflowNode(config ApplicantWithWorkflowNodeConfig) (Applicant, err
 	}
 
 	nodeCfg := config.Node.GetConfigForApply()
+	nodeKeyAlgorithms := xslices.Filter(strings.Split(nodeCfg.KeyAlgorithm, ";"), func(s string) bool { return s != "" })
+	keyAlgorithm := config.KeyAlgorithm
+	if keyAlgorithm == "" && len(nodeKeyAlgorithms) > 0 {
+		keyAlgorithm = nodeKeyAlgorithms[0]
+	}
+
 	options := &applicantProviderOptions{
 		Domains:                 xslices.Filter(strings.Split(nodeCfg.Domains, ";"), func(s string) bool { return s != "" }),
 		ContactEmail:            nodeCfg.ContactEmail,
+		ChallengeType:           domain.ACMEChallengeType(nodeCfg.ChallengeType),
 		Provider:                domain.ACMEDns01ProviderType(nodeCfg.Provider),
 		ProviderAccessConfig:    make(map[string]any),
 		ProviderServiceConfig:   nodeCfg.ProviderConfig,
 		CAProvider:              domain.CAProviderType(nodeCfg.CAProvider),
 		CAProviderAccessConfig:  make(map[string]any),
 		CAProviderServiceConfig: nodeCfg.CAProviderConfig,
-		KeyAlgorithm:            nodeCfg.KeyAlgorithm,
+		KeyAlgorithm:            keyAlgorithm,
 		ACMEProfile:             nodeCfg.ACMEProfile,
 		Nameservers:             xslices.Filter(strings.Split(nodeCfg.Nameservers, ";"), func(s string) bool { return s != "" }),
 		DnsPropagationWait:      nodeCfg.DnsPropagationWait,
 		DnsPropagationTimeout:   nodeCfg.DnsPropagationTimeout,
 		DnsTTL:                  nodeCfg.DnsTTL,
 		DisableFollowCNAME:      nodeCfg.DisableFollowCNAME,
+		RateLimitMaxWait:        nodeCfg.RateLimitMaxWait,
+	}
+
+	// 使用自定义 CSR 时，以 CSR 中的域名为准
+	if nodeCfg.CSR != "" {
+		csr, err := xcert.ParseCertificateRequestFromPEM(nodeCfg.CSR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csr: %w", err)
+		}
+
+		csrDomains := getCertificateRequestDomains(csr)
+		if len(csrDomains) == 0 {
+			return nil, fmt.Errorf("invalid csr: no domains found")
+		}
+		if len(options.Domains) > 0 {
+			configDomains := slices.Clone(options.Domains)
+			sortedCSRDomains := slices.Clone(csrDomains)
+			slices.Sort(configDomains)
+			slices.Sort(sortedCSRDomains)
+			if !slices.Equal(configDomains, sortedCSRDomains) {
+				return nil, fmt.Errorf("the domains in csr do not match the configured domains")
+			}
+		}
+
+		options.CSR = csr
+		options.Domains = csrDomains
+	}
+	if nodeCfg.PrivateKey != "" {
+		privkey, err := xcert.ParsePrivateKeyFromPEM(nodeCfg.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+
+		if options.CSR != nil {
+			signer, ok := privkey.(crypto.Signer)
+			if !ok {
+				return nil, fmt.Errorf("invalid private key: unsupported key type")
+			}
+
+			csrPubkey, ok := options.CSR.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+			if !ok || !csrPubkey.Equal(signer.Public()) {
+				return nil, fmt.Errorf("the private key does not match the public key in csr")
+			}
+		}
+
+		options.PrivateKey = privkey
 	}
 
 	accessRepo := repository.NewAccessRepository()
@@ -77,6 +137,7 @@ func NewWithWorkflowNode(config ApplicantWithWorkflowNodeConfig) (Applicant, err
 		if access, err := accessRepo.GetById(context.Background(), nodeCfg.ProviderAccessId); err != nil {
 			return nil, fmt.Errorf("failed to get access #%s record: %w", nodeCfg.ProviderAccessId, err)
 		} else {
+			options.ProviderAccessId = access.Id
 			options.ProviderAccessConfig = access.Config
 		}
 	}
@@ -109,35 +170,123 @@ func NewWithWorkflowNode(config ApplicantWithWorkflowNodeConfig) (Applicant, err
 		options.CAProviderAccessConfig = sslProviderConfig.Config[options.CAProvider]
 	}
 
+	// 申请多种算法的证书时，分别查找各算法对应的上一张证书
+	var lastCertificate *domain.Certificate
 	certRepo := repository.NewCertificateRepository()
-	lastCertificate, _ := certRepo.GetByWorkflowNodeId(context.Background(), config.Node.Id)
-	if lastCertificate != nil && !lastCertificate.ACMERenewed {
+	if len(nodeKeyAlgorithms) > 1 {
+		lastCertificate, _ = certRepo.GetByWorkflowNodeIdAndKeyAlgorithm(context.Background(), config.Node.Id, domain.CertificateKeyAlgorithmType(keyAlgorithm))
+	} else {
+		lastCertificate, _ = certRepo.GetByWorkflowNodeId(context.Background(), config.Node.Id)
+	}
+	if lastCertificate != nil {
 		newCertSan := slices.Clone(options.Domains)
 		oldCertSan := strings.Split(lastCertificate.SubjectAltNames, ";")
 		slices.Sort(newCertSan)
 		slices.Sort(oldCertSan)
 
 		if slices.Equal(newCertSan, oldCertSan) {
-			lastCertX509, _ := certcrypto.ParsePEMCertificate([]byte(lastCertificate.Certificate))
-			if lastCertX509 != nil {
-				replacedARICertId, _ := certificate.MakeARICertID(lastCertX509)
-				options.ARIReplaceAcct = lastCertificate.ACMEAccountUrl
-				options.ARIReplaceCert = replacedARICertId
+			if !lastCertificate.ACMERenewed {
+				lastCertX509, _ := certcrypto.ParsePEMCertificate([]byte(lastCertificate.Certificate))
+				if lastCertX509 != nil {
+					replacedARICertId, _ := certificate.MakeARICertID(lastCertX509)
+					options.ARIReplaceAcct = lastCertificate.ACMEAccountUrl
+					options.ARIReplaceCert = replacedARICertId
+				}
+			}
+
+			// 复用上次证书的私钥
+			if nodeCfg.ReuseKey && options.CSR == nil && options.PrivateKey == nil {
+				if privkey, createdAt, ok := getReusablePrivateKey(lastCertificate, options.KeyAlgorithm, nodeCfg.ReuseKeyMaxAge); ok {
+					options.PrivateKey = privkey
+					options.PrivateKeyCreatedAt = createdAt
+				} else if config.Logger != nil {
+					config.Logger.Info("the private key of last certificate is not reusable, a new one will be generated")
+				}
 			}
 		}
 	}
 
-	applicant, err := createApplicantProvider(options)
-	if err != nil {
-		return nil, err
+	if len(options.Domains) == 0 {
+		return nil, fmt.Errorf("no domains to issue")
 	}
 
-	return &applicantImpl{
-		applicant: applicant,
-		options:   options,
+	// 主 CA 之后依次为备用 CA
+	candidates := []*applicantProviderOptions{options}
+	for _, fallback := range nodeCfg.CAProviderFallbacks {
+		fallbackOptions := *options
+		fallbackOptions.CAProvider = domain.CAProviderType(fallback.CAProvider)
+		fallbackOptions.CAProviderAccessId = ""
+		fallbackOptions.CAProviderAccessConfig = make(map[string]any)
+		fallbackOptions.CAProviderServiceConfig = fallback.CAProviderConfig
+		if fallback.CAProviderAccessId != "" {
+			if access, err := accessRepo.GetById(context.Background(), fallback.CAProviderAccessId); err != nil {
+				return nil, fmt.Errorf("failed to get access #%s record: %w", fallback.CAProviderAccessId, err)
+			} else {
+				fallbackOptions.CAProviderAccessId = access.Id
+				fallbackOptions.CAProviderAccessConfig = access.Config
+			}
+		}
+
+		candidates = append(candidates, &fallbackOptions)
+	}
+
+	var challengeProvider challenge.Provider
+	applicants := make([]Applicant, 0, len(candidates))
+	for _, candidate := range candidates {
+		// 本地 CA 直接签发证书，无需经过 ACME 质询
+		if candidate.CAProvider == domain.CAProviderTypeLocalCA {
+			applicants = append(applicants, &localCAApplicantImpl{
+				options: candidate,
+			})
+			continue
+		}
+
+		if challengeProvider == nil {
+			provider, err := createChallengeProvider(options)
+			if err != nil {
+				return nil, err
+			}
+
+			challengeProvider = provider
+		}
+
+		applicants = append(applicants, &applicantImpl{
+			applicant: challengeProvider,
+			options:   candidate,
+		})
+	}
+
+	if len(applicants) == 1 {
+		return applicants[0], nil
+	}
+
+	return &failoverApplicantImpl{
+		applicants: applicants,
+		candidates: candidates,
+		logger:     config.Logger,
 	}, nil
 }
 
+func createChallengeProvider(options *applicantProviderOptions) (challenge.Provider, error) {
+	// 通配符域名只能通过 DNS-01 质询验证
+	if options.ChallengeType != domain.ACMEChallengeTypeDNS01 {
+		if slices.ContainsFunc(options.Domains, func(s string) bool { return strings.HasPrefix(s, "*.") }) {
+			return nil, fmt.Errorf("wildcard domains are not supported by challenge type '%s'", string(options.ChallengeType))
+		}
+	}
+
+	switch options.ChallengeType {
+	case domain.ACMEChallengeTypeDNS01:
+		return createApplicantProvider(options)
+	case domain.ACMEChallengeTypeHTTP01:
+		return createHttp01ApplicantProvider(options)
+	case domain.ACMEChallengeTypeTLSALPN01:
+		return createTlsAlpn01ApplicantProvider(options)
+	default:
+		return nil, fmt.Errorf("unsupported challenge type '%s'", string(options.ChallengeType))
+	}
+}
+
 type applicantImpl struct {
 	applicant challenge.Provider
 	options   *applicantProviderOptions
@@ -146,11 +295,25 @@ type applicantImpl struct {
 var _ Applicant = (*applicantImpl)(nil)
 
 func (d *applicantImpl) Apply(ctx context.Context) (*ApplyResult, error) {
+	if err := waitForRateLimits(ctx, d.options); err != nil {
+		return nil, err
+	}
+
 	limiter := getLimiter(fmt.Sprintf("apply_%s", d.options.ContactEmail))
 	if err := limiter.Wait(ctx); err != nil {
 		return nil, err
 	}
 
+	// lego 通过进程环境变量决定是否跟随 CNAME，需在整个申请期间以相同的模式占用
+	// link: https://github.com/go-acme/lego/issues/1867
+	if d.options.ChallengeType != domain.ACMEChallengeTypeHTTP01 && d.options.ChallengeType != domain.ACMEChallengeTypeTLSALPN01 {
+		release, err := acquireLegoCNAMEMode(ctx, d.options.DisableFollowCNAME)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
 	return applyUseLego(d.applicant, d.options)
 }
 
@@ -172,34 +335,28 @@ func applyUseLego(legoProvider challenge.Provider, options *applicantProviderOpt
 		return nil, err
 	}
 
-	// Some unified lego environment variables are configured here.
-	// link: https://github.com/go-acme/lego/issues/1867
-	os.Setenv("LEGO_DISABLE_CNAME_SUPPORT", strconv.FormatBool(options.DisableFollowCNAME))
-
 	// Create an ACME client config
 	config := lego.NewConfig(user)
 	config.Certificate.KeyType = parseLegoKeyAlgorithm(domain.CertificateKeyAlgorithmType(options.KeyAlgorithm))
-	switch user.getCAProvider() {
-	case caSSLCom:
-		if strings.HasPrefix(options.KeyAlgorithm, "RSA") {
-			config.CADirURL = caDirUrls[caSSLCom+"RSA"]
-		} else if strings.HasPrefix(options.KeyAlgorithm, "EC") {
-			config.CADirURL = caDirUrls[caSSLCom+"ECC"]
-		} else {
-			config.CADirURL = caDirUrls[caSSLCom]
-		}
-
-	case caCustom:
-		caDirURL := xmaps.GetString(options.CAProviderAccessConfig, "endpoint")
-		if caDirURL != "" {
-			config.CADirURL = caDirURL
-		} else {
-			return nil, fmt.Errorf("invalid ca provider endpoint")
+	if caDirURL, err := getCADirURL(user, options.KeyAlgorithm, options.CAProviderAccessConfig); err != nil {
+		return nil, err
+	} else {
+		config.CADirURL = caDirURL
+	}
+	if user.getCAProvider() == caCustom {
+		if err := configureCustomCAHTTPClient(config, options.CAProviderAccessConfig); err != nil {
+			return nil, err
 		}
+	}
 
-	default:
-		config.CADirURL = caDirUrls[user.CA]
+	// Capture the Retry-After header of rate-limited responses
+	retryAfterRecorder := &retryAfterTransport{base: config.HTTPClient.Transport}
+	if retryAfterRecorder.base == nil {
+		retryAfterRecorder.base = http.DefaultTransport
 	}
+	httpClient := *config.HTTPClient
+	httpClient.Transport = retryAfterRecorder
+	config.HTTPClient = &httpClient
 
 	// Create an ACME client
 	client, err := lego.NewClient(config)
@@ -207,44 +364,77 @@ func applyUseLego(legoProvider challenge.Provider, options *applicantProviderOpt
 		return nil, err
 	}
 
-	// Set the DNS01 challenge provider
-	client.Challenge.SetDNS01Provider(legoProvider,
-		dns01.CondOption(
-			len(options.Nameservers) > 0,
-			dns01.AddRecursiveNameservers(dns01.ParseNameservers(options.Nameservers)),
-		),
-		dns01.CondOption(
-			options.DnsPropagationWait > 0,
-			dns01.PropagationWait(time.Duration(options.DnsPropagationWait)*time.Second, true),
-		),
-		dns01.CondOption(
-			len(options.Nameservers) > 0 || options.DnsPropagationWait > 0,
-			dns01.DisableAuthoritativeNssPropagationRequirement(),
-		),
-	)
+	// Set the challenge provider
+	switch options.ChallengeType {
+	case domain.ACMEChallengeTypeHTTP01:
+		if err := client.Challenge.SetHTTP01Provider(legoProvider); err != nil {
+			return nil, err
+		}
+
+	case domain.ACMEChallengeTypeTLSALPN01:
+		if err := client.Challenge.SetTLSALPN01Provider(legoProvider); err != nil {
+			return nil, err
+		}
+
+	default:
+		if err := client.Challenge.SetDNS01Provider(legoProvider,
+			dns01.CondOption(
+				len(options.Nameservers) > 0,
+				dns01.AddRecursiveNameservers(dns01.ParseNameservers(options.Nameservers)),
+			),
+			dns01.CondOption(
+				options.DnsPropagationWait > 0,
+				dns01.PropagationWait(time.Duration(options.DnsPropagationWait)*time.Second, true),
+			),
+			dns01.CondOption(
+				len(options.Nameservers) > 0 || options.DnsPropagationWait > 0,
+				dns01.DisableAuthoritativeNssPropagationRequirement(),
+			),
+		); err != nil {
+			return nil, err
+		}
+	}
 
 	// New users need to register first
 	if !user.hasRegistration() {
 		reg, err := registerAcmeUserWithSingleFlight(client, user, options.CAProviderAccessConfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed to register acme user: %w", err)
+			return nil, handleRateLimitFailure(options, fmt.Errorf("failed to register acme user: %w", err), retryAfterRecorder.RetryAfter())
 		}
 		user.Registration = reg
 	}
 
 	// Obtain a certificate
-	certRequest := certificate.ObtainRequest{
-		Domains: options.Domains,
-		Bundle:  true,
-		Profile: options.ACMEProfile,
-	}
+	var replacesCertID string
 	if options.ARIReplaceAcct == user.Registration.URI {
-		certRequest.ReplacesCertID = options.ARIReplaceCert
+		replacesCertID = options.ARIReplaceCert
+	}
+
+	privkeyCreatedAt := options.PrivateKeyCreatedAt
+	if privkeyCreatedAt.IsZero() {
+		privkeyCreatedAt = time.Now()
 	}
 
-	certResource, err := client.Certificate.Obtain(certRequest)
+	var certResource *certificate.Resource
+	if options.CSR != nil {
+		certResource, err = client.Certificate.ObtainForCSR(certificate.ObtainForCSRRequest{
+			CSR:            options.CSR,
+			PrivateKey:     options.PrivateKey,
+			Bundle:         true,
+			Profile:        options.ACMEProfile,
+			ReplacesCertID: replacesCertID,
+		})
+	} else {
+		certResource, err = client.Certificate.Obtain(certificate.ObtainRequest{
+			Domains:        options.Domains,
+			PrivateKey:     options.PrivateKey,
+			Bundle:         true,
+			Profile:        options.ACMEProfile,
+			ReplacesCertID: replacesCertID,
+		})
+	}
 	if err != nil {
-		return nil, err
+		return nil, handleRateLimitFailure(options, err, retryAfterRecorder.RetryAfter())
 	}
 
 	return &ApplyResult{
@@ -255,10 +445,77 @@ func applyUseLego(legoProvider challenge.Provider, options *applicantProviderOpt
 		ACMEAccountUrl:       user.Registration.URI,
 		ACMECertUrl:          certResource.CertURL,
 		ACMECertStableUrl:    certResource.CertStableURL,
-		ARIReplaced:          certRequest.ReplacesCertID != "",
+		ARIReplaced:          replacesCertID != "",
+		PrivateKeyCreatedAt:  privkeyCreatedAt,
+		CAProvider:           user.getCAProvider(),
 	}, nil
 }
 
+func getCADirURL(user *acmeUser, keyAlgorithm string, caAccessConfig map[string]any) (string, error) {
+	switch user.getCAProvider() {
+	case caSSLCom:
+		if strings.HasPrefix(keyAlgorithm, "RSA") {
+			return caDirUrls[caSSLCom+"RSA"], nil
+		} else if strings.HasPrefix(keyAlgorithm, "EC") {
+			return caDirUrls[caSSLCom+"ECC"], nil
+		} else {
+			return caDirUrls[caSSLCom], nil
+		}
+
+	case caCustom:
+		caDirURL := xmaps.GetString(caAccessConfig, "endpoint")
+		if caDirURL != "" {
+			return caDirURL, nil
+		} else {
+			return "", fmt.Errorf("invalid ca provider endpoint")
+		}
+
+	default:
+		if caDirURL, ok := caDirUrls[user.CA]; ok {
+			return caDirURL, nil
+		}
+		return "", fmt.Errorf("unsupported ca provider '%s'", user.CA)
+	}
+}
+
+func getReusablePrivateKey(lastCertificate *domain.Certificate, keyAlgorithm string, maxAgeDays int32) (crypto.PrivateKey, time.Time, bool) {
+	if lastCertificate.PrivateKey == "" || lastCertificate.IsRevoked() {
+		return nil, time.Time{}, false
+	}
+	if string(lastCertificate.KeyAlgorithm) != keyAlgorithm {
+		return nil, time.Time{}, false
+	}
+
+	// 早期版本的证书记录没有私钥创建时间，以证书生效时间代替
+	createdAt := lastCertificate.KeyCreatedAt
+	if createdAt.IsZero() {
+		createdAt = lastCertificate.EffectAt
+	}
+	if maxAgeDays > 0 && time.Since(createdAt) >= time.Duration(maxAgeDays)*24*time.Hour {
+		return nil, time.Time{}, false
+	}
+
+	privkey, err := xcert.ParsePrivateKeyFromPEM(lastCertificate.PrivateKey)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	return privkey, createdAt, true
+}
+
+func getCertificateRequestDomains(csr *x509.CertificateRequest) []string {
+	domains := make([]string, 0)
+	if csr.Subject.CommonName != "" {
+		domains = append(domains, csr.Subject.CommonName)
+	}
+	for _, name := range csr.DNSNames {
+		if !slices.Contains(domains, name) {
+			domains = append(domains, name)
+		}
+	}
+	return domains
+}
+
 func parseLegoKeyAlgorithm(algo domain.CertificateKeyAlgorithmType) certcrypto.KeyType {
 	alogMap := map[domain.CertificateKeyAlgorithmType]certcrypto.KeyType{
 		domain.CertificateKeyAlgorithmTypeRSA2048: certcrypto.RSA2048,
@@ -276,3 +533,24 @@ func parseLegoKeyAlgorithm(algo domain.CertificateKeyAlgorithmType) certcrypto.K
 
 	return certcrypto.RSA2048
 }
+
+// 返回首次连接信任 SSH 主机公钥后，将其指纹固定到授权中的回调。
+// 未关联授权时返回 nil，此时无法固定主机公钥，校验模式 "tofu" 将按 "strict" 处理。
+func newSSHHostKeyTrustedCallback(accessId string) func(serverIndex int, fingerprint string) error {
+	if accessId == "" {
+		return nil
+	}
+
+	return func(serverIndex int, fingerprint string) error {
+		accessRepo := repository.NewAccessRepository()
+
+		_, err := accessRepo.Update(context.Background(), accessId, func(access *domain.Access) error {
+			return access.SetSSHHostKeyFingerprint(0, serverIndex, fingerprint)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save ssh host key fingerprint to access #%s: %w", accessId, err)
+		}
+
+		return nil
+	}
+}
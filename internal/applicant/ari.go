@@ -0,0 +1,64 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme/api"
+	"github.com/go-acme/lego/v4/certificate"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
+)
+
+// ErrNoARI 表示 CA 不支持 ACME Renewal Information (ARI)。
+var ErrNoARI = api.ErrNoARI
+
+type RenewalInfo struct {
+	// 建议续期窗口的开始时间。
+	WindowStart time.Time
+	// 建议续期窗口的结束时间。
+	WindowEnd time.Time
+	// CA 对续期建议的解释说明地址。
+	ExplanationURL string
+	// CA 建议的下次查询间隔。
+	RetryAfter time.Duration
+}
+
+// 向签发证书的 CA 查询 ACME Renewal Information (ARI)。
+//
+// 入参:
+//   - ctx: 上下文。
+//   - cert: 由工作流申请的证书。
+//
+// 出参:
+//   - info: 续期建议信息。
+//   - err: 错误。当 CA 不支持 ARI 时，返回 [ErrNoARI]。
+func GetRenewalInfo(ctx context.Context, cert *domain.Certificate) (*RenewalInfo, error) {
+	if cert == nil {
+		return nil, errors.New("certificate is nil")
+	}
+
+	certX509, err := xcert.ParseCertificateFromPEM(cert.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newAcmeClientWithCertificateAccount(ctx, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Certificate.GetRenewalInfo(certificate.RenewalInfoRequest{Cert: certX509})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RenewalInfo{
+		WindowStart:    resp.SuggestedWindow.Start,
+		WindowEnd:      resp.SuggestedWindow.End,
+		ExplanationURL: resp.ExplanationURL,
+		RetryAfter:     resp.RetryAfter,
+	}, nil
+}
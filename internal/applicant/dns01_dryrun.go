@@ -0,0 +1,183 @@
+package applicant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/miekg/dns"
+	"golang.org/x/exp/slices"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+)
+
+// 未指定 DNS 服务器且无法读取系统配置时使用的默认 DNS 服务器，与 lego 保持一致。
+var dns01DryRunDefaultNameservers = []string{
+	"google-public-dns-a.google.com:53",
+	"google-public-dns-b.google.com:53",
+}
+
+// 预演时单次 DNS 查询的超时时间。
+const dns01DryRunQueryTimeout = 10 * time.Second
+
+// 对 DNS-01 质询提供商进行预演：
+// 创建 `_acme-challenge` TXT 记录，检查其在各 DNS 服务器上是否可见，最后删除该记录，并记录各阶段耗时。
+// 预演不会与 CA 交互，因此不会消耗 CA 的验证失败次数。
+func dryRunDns01Provider(ctx context.Context, options *applicantProviderOptions, testDomain string) (*dtos.ApplicantTestDns01ProviderResp, error) {
+	provider, err := createApplicantProvider(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dns-01 provider: %w", err)
+	}
+
+	keyAuthBytes := make([]byte, 32)
+	if _, err := rand.Read(keyAuthBytes); err != nil {
+		return nil, err
+	}
+	keyAuth := base64.RawURLEncoding.EncodeToString(keyAuthBytes)
+	keyAuthShaBytes := sha256.Sum256([]byte(keyAuth))
+	challengeValue := base64.RawURLEncoding.EncodeToString(keyAuthShaBytes[:])
+
+	nameservers := dns01.ParseNameservers(options.Nameservers)
+	if len(nameservers) == 0 {
+		if config, err := dns.ClientConfigFromFile("/etc/resolv.conf"); err == nil && len(config.Servers) > 0 {
+			nameservers = dns01.ParseNameservers(config.Servers)
+		} else {
+			nameservers = dns01DryRunDefaultNameservers
+		}
+	}
+
+	timeout, interval := dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+	if p, ok := provider.(challenge.ProviderTimeout); ok {
+		timeout, interval = p.Timeout()
+	}
+
+	// 不读取进程环境变量，以免受同时运行的申请影响
+	challengeFQDN := getDns01EffectiveFQDN(testDomain, !options.DisableFollowCNAME, nameservers)
+
+	res := &dtos.ApplicantTestDns01ProviderResp{
+		Domain:      testDomain,
+		FQDN:        challengeFQDN,
+		Value:       challengeValue,
+		Nameservers: make([]*dtos.ApplicantTestDns01ProviderNameserverResult, 0, len(nameservers)),
+	}
+	startedAt := time.Now()
+	defer func() {
+		res.TotalDuration = time.Since(startedAt).Milliseconds()
+	}()
+
+	// 提供商内部由 lego 决定是否跟随 CNAME，需以相同的模式占用，避免与其他模式的申请同时运行
+	release, err := acquireLegoCNAMEMode(ctx, options.DisableFollowCNAME)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// 创建 TXT 记录
+	presentStartedAt := time.Now()
+	err = provider.Present(testDomain, "", keyAuth)
+	res.PresentDuration = time.Since(presentStartedAt).Milliseconds()
+	if err != nil {
+		res.Error = fmt.Sprintf("failed to present txt record: %s", err.Error())
+		return res, nil
+	}
+
+	// 删除 TXT 记录
+	defer func() {
+		cleanupStartedAt := time.Now()
+		err := provider.CleanUp(testDomain, "", keyAuth)
+		res.CleanUpDuration = time.Since(cleanupStartedAt).Milliseconds()
+		if err != nil {
+			res.Success = false
+			if res.Error == "" {
+				res.Error = fmt.Sprintf("failed to clean up txt record: %s", err.Error())
+			}
+		}
+	}()
+
+	// 检查 TXT 记录是否已在各 DNS 服务器上可见
+	propagationStartedAt := time.Now()
+	for _, nameserver := range nameservers {
+		res.Nameservers = append(res.Nameservers, &dtos.ApplicantTestDns01ProviderNameserverResult{Nameserver: nameserver})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+polling:
+	for {
+		pending := false
+		for _, result := range res.Nameservers {
+			if result.Visible {
+				continue
+			}
+
+			visible, err := lookupDns01TXTRecord(challengeFQDN, challengeValue, result.Nameserver)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Error = ""
+			}
+			if visible {
+				result.Visible = true
+				result.Duration = time.Since(propagationStartedAt).Milliseconds()
+			} else {
+				pending = true
+			}
+		}
+		if !pending {
+			break
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			break polling
+		case <-timer.C:
+		}
+	}
+	res.PropagationDuration = time.Since(propagationStartedAt).Milliseconds()
+
+	res.Success = !slices.ContainsFunc(res.Nameservers, func(r *dtos.ApplicantTestDns01ProviderNameserverResult) bool { return !r.Visible })
+	if !res.Success {
+		res.Error = fmt.Sprintf("txt record '%s' is not visible from all nameservers after %s", challengeFQDN, timeout)
+	}
+
+	return res, nil
+}
+
+func lookupDns01TXTRecord(fqdn string, value string, nameserver string) (bool, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(fqdn, dns.TypeTXT)
+	msg.SetEdns0(4096, false)
+	msg.RecursionDesired = true
+
+	client := &dns.Client{Timeout: dns01DryRunQueryTimeout}
+	in, _, err := client.Exchange(msg, nameserver)
+	if err == nil && in.Truncated {
+		client.Net = "tcp"
+		in, _, err = client.Exchange(msg, nameserver)
+	}
+	if err != nil {
+		return false, err
+	}
+	if in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError {
+		return false, fmt.Errorf("unexpected response code '%s'", dns.RcodeToString[in.Rcode])
+	}
+
+	for _, rr := range in.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			if strings.Join(txt.Txt, "") == value {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,82 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/go-acme/lego/v4/acme"
+)
+
+// 依次尝试多个 CA 的申请器。
+// 当前一个 CA 因限流、服务端错误或超时而失败时，回退至下一个 CA；其他错误将直接返回。
+type failoverApplicantImpl struct {
+	applicants []Applicant
+	candidates []*applicantProviderOptions
+	logger     *slog.Logger
+}
+
+var _ Applicant = (*failoverApplicantImpl)(nil)
+
+func (d *failoverApplicantImpl) Apply(ctx context.Context) (*ApplyResult, error) {
+	var errs []error
+	for i, applicant := range d.applicants {
+		caProvider := string(d.candidates[i].CAProvider)
+
+		res, err := applicant.Apply(ctx)
+		if err == nil {
+			if i > 0 && d.logger != nil {
+				d.logger.Info("certificate issued by fallback ca", slog.String("caProvider", caProvider))
+			}
+			return res, nil
+		}
+
+		errs = append(errs, err)
+		if ctx.Err() != nil || !isCAFailoverError(err) {
+			return nil, err
+		}
+
+		if i < len(d.applicants)-1 && d.logger != nil {
+			d.logger.Warn("failed to apply certificate from ca, falling back to the next one", slog.String("caProvider", caProvider), slog.String("nextCAProvider", string(d.candidates[i+1].CAProvider)), slog.Any("error", err))
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// 判断错误是否应当回退至下一个 CA：限流、服务端错误或网络超时。
+func isCAFailoverError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var rlErr *RateLimitError
+	if errors.As(err, &rlErr) {
+		return true
+	}
+
+	var problem *acme.ProblemDetails
+	if errors.As(err, &problem) {
+		switch problem.Type {
+		case "urn:ietf:params:acme:error:rateLimited", "urn:ietf:params:acme:error:serverInternal":
+			return true
+		}
+		return problem.HTTPStatus == http.StatusTooManyRequests || problem.HTTPStatus >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// 无法连接到 CA 服务端
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,137 @@
+package applicant
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// lego 仅通过进程级的环境变量 `LEGO_DISABLE_CNAME_SUPPORT` 控制 DNS-01 质询是否跟随 CNAME，
+// 且在每次创建 TXT 记录、检查传播时才读取该变量。
+// 因此同一时刻只允许以同一种模式运行：相同模式的申请或预演可以并发，切换模式前需等待另一模式的所有持有者释放，
+// 以免某次申请在执行过程中被其他请求修改了 CNAME 跟随行为。
+// 为避免某一模式被持续占用而使另一模式无限期等待，一旦有等待另一模式的调用者，当前模式的新调用者也需排在其后。
+var legoCNAMEMode = struct {
+	mtx      sync.Mutex
+	disabled bool
+	holders  int
+	waiters  map[bool]int
+	changed  chan struct{}
+}{
+	waiters: make(map[bool]int),
+	changed: make(chan struct{}),
+}
+
+// 以指定的 CNAME 跟随模式占用 lego 的 DNS-01 质询，返回的函数用于释放占用。
+func acquireLegoCNAMEMode(ctx context.Context, disableFollowCNAME bool) (release func(), err error) {
+	waiting := false
+
+	for {
+		legoCNAMEMode.mtx.Lock()
+		if canAcquireLegoCNAMEMode(disableFollowCNAME) {
+			if waiting {
+				legoCNAMEMode.waiters[disableFollowCNAME]--
+			}
+			if legoCNAMEMode.holders == 0 {
+				legoCNAMEMode.disabled = disableFollowCNAME
+				os.Setenv("LEGO_DISABLE_CNAME_SUPPORT", strconv.FormatBool(disableFollowCNAME))
+			}
+			legoCNAMEMode.holders++
+			legoCNAMEMode.mtx.Unlock()
+
+			var once sync.Once
+			return func() {
+				once.Do(func() {
+					legoCNAMEMode.mtx.Lock()
+					defer legoCNAMEMode.mtx.Unlock()
+
+					legoCNAMEMode.holders--
+					if legoCNAMEMode.holders == 0 {
+						notifyLegoCNAMEModeChanged()
+					}
+				})
+			}, nil
+		}
+
+		if !waiting {
+			waiting = true
+			legoCNAMEMode.waiters[disableFollowCNAME]++
+		}
+		changed := legoCNAMEMode.changed
+		legoCNAMEMode.mtx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			legoCNAMEMode.mtx.Lock()
+			legoCNAMEMode.waiters[disableFollowCNAME]--
+			if legoCNAMEMode.waiters[disableFollowCNAME] == 0 {
+				// 排在其后的调用者可能因此可以占用
+				notifyLegoCNAMEModeChanged()
+			}
+			legoCNAMEMode.mtx.Unlock()
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// 判断能否以指定的模式占用，调用前需持有锁。
+func canAcquireLegoCNAMEMode(disableFollowCNAME bool) bool {
+	othersWaiting := legoCNAMEMode.waiters[!disableFollowCNAME] > 0
+
+	if legoCNAMEMode.holders == 0 {
+		// 无人占用时，若两种模式均有等待者，则轮到与上次不同的模式
+		return !othersWaiting || legoCNAMEMode.disabled != disableFollowCNAME
+	}
+
+	return legoCNAMEMode.disabled == disableFollowCNAME && !othersWaiting
+}
+
+// 唤醒所有等待者重新检查能否占用，调用前需持有锁。
+func notifyLegoCNAMEModeChanged() {
+	close(legoCNAMEMode.changed)
+	legoCNAMEMode.changed = make(chan struct{})
+}
+
+// 获取 DNS-01 质询的实际 FQDN。与 lego 的实现一致，但是否跟随 CNAME 由参数决定而非进程环境变量。
+func getDns01EffectiveFQDN(domain string, followCNAME bool, nameservers []string) string {
+	fqdn := dns.Fqdn("_acme-challenge." + domain)
+	if !followCNAME {
+		return fqdn
+	}
+
+	client := &dns.Client{Timeout: dns01DryRunQueryTimeout}
+	for range 50 {
+		msg := new(dns.Msg)
+		msg.SetQuestion(fqdn, dns.TypeCNAME)
+		msg.RecursionDesired = true
+
+		var cname string
+		for _, nameserver := range nameservers {
+			in, _, err := client.Exchange(msg, nameserver)
+			if err != nil || in.Rcode != dns.RcodeSuccess {
+				continue
+			}
+
+			for _, rr := range in.Answer {
+				if cn, ok := rr.(*dns.CNAME); ok && strings.EqualFold(cn.Hdr.Name, fqdn) {
+					cname = cn.Target
+					break
+				}
+			}
+			break
+		}
+
+		if cname == "" || strings.EqualFold(cname, fqdn) {
+			break
+		}
+
+		fqdn = cname
+	}
+
+	return fqdn
+}
@@ -0,0 +1,123 @@
+package applicant
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func waitLegoCNAMEModeWaiters(t *testing.T, disableFollowCNAME bool, want int) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		legoCNAMEMode.mtx.Lock()
+		got := legoCNAMEMode.waiters[disableFollowCNAME]
+		legoCNAMEMode.mtx.Unlock()
+		if got == want {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("waiters of mode %v = %d, want %d", disableFollowCNAME, got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func acquireLegoCNAMEModeAsync(ctx context.Context, disableFollowCNAME bool) <-chan func() {
+	acquired := make(chan func(), 1)
+	go func() {
+		release, err := acquireLegoCNAMEMode(ctx, disableFollowCNAME)
+		if err != nil {
+			close(acquired)
+			return
+		}
+		acquired <- release
+	}()
+	return acquired
+}
+
+func assertLegoCNAMEModeNotAcquired(t *testing.T, acquired <-chan func()) {
+	t.Helper()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired the mode before the queued caller of the other mode")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func assertLegoCNAMEModeEnv(t *testing.T, disableFollowCNAME bool) {
+	t.Helper()
+
+	if got := os.Getenv("LEGO_DISABLE_CNAME_SUPPORT"); got != strconv.FormatBool(disableFollowCNAME) {
+		t.Errorf("LEGO_DISABLE_CNAME_SUPPORT = '%s', want '%v'", got, disableFollowCNAME)
+	}
+}
+
+func TestAcquireLegoCNAMEMode_Alternate(t *testing.T) {
+	ctx := context.Background()
+
+	release, err := acquireLegoCNAMEMode(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLegoCNAMEModeEnv(t, false)
+
+	// 等待另一模式的调用者排队后，当前模式的新调用者需排在其后
+	disabledAcquired := acquireLegoCNAMEModeAsync(ctx, true)
+	waitLegoCNAMEModeWaiters(t, true, 1)
+	enabledAcquired := acquireLegoCNAMEModeAsync(ctx, false)
+	waitLegoCNAMEModeWaiters(t, false, 1)
+	assertLegoCNAMEModeNotAcquired(t, disabledAcquired)
+	assertLegoCNAMEModeNotAcquired(t, enabledAcquired)
+
+	release()
+	release = <-disabledAcquired
+	assertLegoCNAMEModeEnv(t, true)
+
+	// 反之亦然
+	disabledAcquired = acquireLegoCNAMEModeAsync(ctx, true)
+	waitLegoCNAMEModeWaiters(t, true, 1)
+	assertLegoCNAMEModeNotAcquired(t, enabledAcquired)
+	assertLegoCNAMEModeNotAcquired(t, disabledAcquired)
+
+	release()
+	release = <-enabledAcquired
+	assertLegoCNAMEModeEnv(t, false)
+
+	release()
+	release = <-disabledAcquired
+	assertLegoCNAMEModeEnv(t, true)
+	release()
+}
+
+func TestAcquireLegoCNAMEMode_CancelledWaiter(t *testing.T) {
+	release, err := acquireLegoCNAMEMode(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	disabledAcquired := acquireLegoCNAMEModeAsync(ctx, true)
+	waitLegoCNAMEModeWaiters(t, true, 1)
+	enabledAcquired := acquireLegoCNAMEModeAsync(context.Background(), false)
+	waitLegoCNAMEModeWaiters(t, false, 1)
+
+	// 排在前面的调用者放弃等待后，当前模式的调用者可以立即占用
+	cancel()
+	if _, ok := <-disabledAcquired; ok {
+		t.Fatal("the cancelled caller should not acquire the mode")
+	}
+
+	select {
+	case release := <-enabledAcquired:
+		release()
+	case <-time.After(5 * time.Second):
+		t.Fatal("the caller of the current mode is not woken after the other caller cancelled")
+	}
+	assertLegoCNAMEModeEnv(t, false)
+}
@@ -0,0 +1,337 @@
+package applicant
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
+	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
+	xslices "github.com/certimate-go/certimate/pkg/utils/slices"
+)
+
+const (
+	localCADefaultValidityDays             = 90
+	localCADefaultRootValidityDays         = 3650
+	localCADefaultIntermediateValidityDays = 1825
+	localCADefaultCommonName               = "Certimate Local"
+)
+
+var localCAExtKeyUsages = map[string]x509.ExtKeyUsage{
+	"serverAuth":      x509.ExtKeyUsageServerAuth,
+	"clientAuth":      x509.ExtKeyUsageClientAuth,
+	"codeSigning":     x509.ExtKeyUsageCodeSigning,
+	"emailProtection": x509.ExtKeyUsageEmailProtection,
+	"timeStamping":    x509.ExtKeyUsageTimeStamping,
+	"ocspSigning":     x509.ExtKeyUsageOCSPSigning,
+}
+
+// 内置的本地私有 CA。
+// 由根证书和可选的中间证书组成；存在中间证书时由中间证书签发，否则由根证书签发。
+type localCA struct {
+	root         *x509.Certificate
+	intermediate *x509.Certificate
+	signer       crypto.Signer
+}
+
+func (ca *localCA) issuer() *x509.Certificate {
+	if ca.intermediate != nil {
+		return ca.intermediate
+	}
+	return ca.root
+}
+
+// 从授权配置中加载本地 CA，并校验证书链与签发私钥。
+func loadLocalCA(accessConfig map[string]any) (*localCA, error) {
+	access := domain.AccessConfigForLocalCA{}
+	if err := xmaps.Populate(accessConfig, &access); err != nil {
+		return nil, fmt.Errorf("failed to populate provider access config: %w", err)
+	}
+
+	if access.RootCertificate == "" {
+		return nil, errors.New("the root certificate of local ca is not configured")
+	}
+
+	rootX509, err := xcert.ParseCertificateFromPEM(access.RootCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root certificate: %w", err)
+	}
+	if !rootX509.IsCA {
+		return nil, errors.New("invalid root certificate: not a ca certificate")
+	}
+	if err := rootX509.CheckSignatureFrom(rootX509); err != nil {
+		return nil, fmt.Errorf("invalid root certificate: not self-signed: %w", err)
+	}
+
+	ca := &localCA{root: rootX509}
+
+	signerPEM := access.RootPrivateKey
+	if access.IntermediateCertificate != "" {
+		intermediateX509, err := xcert.ParseCertificateFromPEM(access.IntermediateCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intermediate certificate: %w", err)
+		}
+		if !intermediateX509.IsCA {
+			return nil, errors.New("invalid intermediate certificate: not a ca certificate")
+		}
+		if err := intermediateX509.CheckSignatureFrom(rootX509); err != nil {
+			return nil, fmt.Errorf("invalid intermediate certificate: not issued by the root certificate: %w", err)
+		}
+
+		ca.intermediate = intermediateX509
+		signerPEM = access.IntermediatePrivateKey
+	}
+
+	if signerPEM == "" {
+		return nil, errors.New("the private key of local ca is not configured")
+	}
+
+	privkey, err := xcert.ParsePrivateKeyFromPEM(signerPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key of local ca: %w", err)
+	}
+
+	signer, ok := privkey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("invalid private key of local ca: unsupported key type")
+	}
+	if pubkey, ok := ca.issuer().PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !pubkey.Equal(signer.Public()) {
+		return nil, errors.New("the private key of local ca does not match its certificate")
+	}
+
+	ca.signer = signer
+	return ca, nil
+}
+
+// 生成新的本地 CA，包含一张自签名根证书和一张由其签发的中间证书。
+func generateLocalCA(commonName string, organization string, keyAlgorithm string, rootValidityDays int32, intermediateValidityDays int32) (*domain.AccessConfigForLocalCA, error) {
+	if commonName == "" {
+		commonName = localCADefaultCommonName
+	}
+	if rootValidityDays <= 0 {
+		rootValidityDays = localCADefaultRootValidityDays
+	}
+	if intermediateValidityDays <= 0 {
+		intermediateValidityDays = localCADefaultIntermediateValidityDays
+	}
+
+	keyType := parseLegoKeyAlgorithm(domain.CertificateKeyAlgorithmType(keyAlgorithm))
+	subject := func(cn string) pkix.Name {
+		name := pkix.Name{CommonName: cn}
+		if organization != "" {
+			name.Organization = []string{organization}
+		}
+		return name
+	}
+
+	now := time.Now()
+
+	rootKey, err := certcrypto.GeneratePrivateKey(keyType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate root private key: %w", err)
+	}
+	rootTemplate := &x509.Certificate{
+		Subject:               subject(commonName + " Root CA"),
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.AddDate(0, 0, int(rootValidityDays)),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            1,
+	}
+	rootX509, err := createLocalCACertificate(rootTemplate, nil, rootKey.(crypto.Signer).Public(), rootKey.(crypto.Signer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create root certificate: %w", err)
+	}
+
+	intermediateKey, err := certcrypto.GeneratePrivateKey(keyType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate intermediate private key: %w", err)
+	}
+	intermediateTemplate := &x509.Certificate{
+		Subject:               subject(commonName + " Intermediate CA"),
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.AddDate(0, 0, int(intermediateValidityDays)),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            0,
+		MaxPathLenZero:        true,
+	}
+	intermediateX509, err := createLocalCACertificate(intermediateTemplate, rootX509, intermediateKey.(crypto.Signer).Public(), rootKey.(crypto.Signer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create intermediate certificate: %w", err)
+	}
+
+	rootCertPEM, _ := xcert.ConvertCertificateToPEM(rootX509)
+	intermediateCertPEM, _ := xcert.ConvertCertificateToPEM(intermediateX509)
+	return &domain.AccessConfigForLocalCA{
+		RootCertificate:         strings.TrimSpace(rootCertPEM),
+		RootPrivateKey:          strings.TrimSpace(string(certcrypto.PEMEncode(rootKey))),
+		IntermediateCertificate: strings.TrimSpace(intermediateCertPEM),
+		IntermediatePrivateKey:  strings.TrimSpace(string(certcrypto.PEMEncode(intermediateKey))),
+	}, nil
+}
+
+func createLocalCACertificate(template *x509.Certificate, parent *x509.Certificate, pubkey crypto.PublicKey, signer crypto.Signer) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serialNumber
+
+	// 证书有效期不得超过签发者的有效期
+	if parent == nil {
+		parent = template
+	} else if template.NotAfter.After(parent.NotAfter) {
+		template.NotAfter = parent.NotAfter
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, parent, pubkey, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(certDER)
+}
+
+func parseLocalCAExtKeyUsages(value string) ([]x509.ExtKeyUsage, error) {
+	names := xslices.Filter(strings.Split(value, ";"), func(s string) bool { return s != "" })
+	if len(names) == 0 {
+		return []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, nil
+	}
+
+	usages := make([]x509.ExtKeyUsage, 0, len(names))
+	for _, name := range names {
+		usage, ok := localCAExtKeyUsages[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported extended key usage '%s'", name)
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, nil
+}
+
+type localCAApplicantImpl struct {
+	options *applicantProviderOptions
+}
+
+var _ Applicant = (*localCAApplicantImpl)(nil)
+
+func (d *localCAApplicantImpl) Apply(ctx context.Context) (*ApplyResult, error) {
+	ca, err := loadLocalCA(d.options.CAProviderAccessConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	validityDays := xmaps.GetOrDefaultInt32(d.options.CAProviderServiceConfig, "validityDays", localCADefaultValidityDays)
+	if validityDays <= 0 {
+		validityDays = localCADefaultValidityDays
+	}
+
+	extKeyUsages, err := parseLocalCAExtKeyUsages(xmaps.GetString(d.options.CAProviderServiceConfig, "extKeyUsages"))
+	if err != nil {
+		return nil, err
+	}
+
+	// 确定证书公钥：优先使用自定义 CSR，其次使用指定的私钥，否则生成新的私钥
+	var pubkey crypto.PublicKey
+	var privkey crypto.PrivateKey
+	privkeyCreatedAt := d.options.PrivateKeyCreatedAt
+	switch {
+	case d.options.CSR != nil:
+		pubkey = d.options.CSR.PublicKey
+		privkey = d.options.PrivateKey
+
+	case d.options.PrivateKey != nil:
+		privkey = d.options.PrivateKey
+
+	default:
+		privkey, err = certcrypto.GeneratePrivateKey(parseLegoKeyAlgorithm(domain.CertificateKeyAlgorithmType(d.options.KeyAlgorithm)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate private key: %w", err)
+		}
+		privkeyCreatedAt = time.Now()
+	}
+	if pubkey == nil {
+		signer, ok := privkey.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+		pubkey = signer.Public()
+	}
+	if privkeyCreatedAt.IsZero() {
+		privkeyCreatedAt = time.Now()
+	}
+
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := pubkey.(*rsa.PublicKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: d.options.Domains[0]},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.AddDate(0, 0, int(validityDays)),
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           extKeyUsages,
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+	}
+	for _, name := range d.options.Domains {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	certX509, err := createLocalCACertificate(template, ca.issuer(), pubkey, ca.signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate: %w", err)
+	}
+
+	// 证书链中不包含根证书
+	certPEM, _ := xcert.ConvertCertificateToPEM(certX509)
+	issuerCertPEM, _ := xcert.ConvertCertificateToPEM(ca.issuer())
+	fullChainCertPEM := certPEM
+	if ca.intermediate != nil {
+		fullChainCertPEM += issuerCertPEM
+	}
+
+	var csrPEM, privkeyPEM string
+	if d.options.CSR != nil {
+		csrPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: d.options.CSR.Raw}))
+	}
+	if privkey != nil {
+		block := certcrypto.PEMBlock(privkey)
+		if block == nil {
+			return nil, errors.New("unsupported private key type")
+		}
+		privkeyPEM = string(pem.EncodeToMemory(block))
+	}
+
+	return &ApplyResult{
+		CSR:                  strings.TrimSpace(csrPEM),
+		FullChainCertificate: strings.TrimSpace(fullChainCertPEM),
+		IssuerCertificate:    strings.TrimSpace(issuerCertPEM),
+		PrivateKey:           strings.TrimSpace(privkeyPEM),
+		PrivateKeyCreatedAt:  privkeyCreatedAt,
+		CAProvider:           string(domain.CAProviderTypeLocalCA),
+	}, nil
+}
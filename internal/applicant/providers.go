@@ -1,11 +1,15 @@
 package applicant
 
 import (
+	"crypto"
+	"crypto/x509"
 	"fmt"
+	"time"
 
 	"github.com/go-acme/lego/v4/challenge"
 
 	"github.com/certimate-go/certimate/internal/domain"
+	pACMEDNS "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/acmedns"
 	pACMEHttpReq "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/acmehttpreq"
 	pAliyun "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/aliyun"
 	pAliyunESA "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/aliyun-esa"
@@ -23,6 +27,7 @@ import (
 	pDNSLA "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/dnsla"
 	pDuckDNS "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/duckdns"
 	pDynv6 "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/dynv6"
+	pExec "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/exec"
 	pGcore "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/gcore"
 	pGname "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/gname"
 	pGoDaddy "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/godaddy"
@@ -38,6 +43,7 @@ import (
 	pPorkbun "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/porkbun"
 	pPowerDNS "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/powerdns"
 	pRainYun "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/rainyun"
+	pRFC2136 "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/rfc2136"
 	pSpaceship "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/spaceship"
 	pTencentCloud "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/tencentcloud"
 	pTencentCloudEO "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/tencentcloud-eo"
@@ -45,13 +51,19 @@ import (
 	pVercel "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/vercel"
 	pVolcEngine "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/volcengine"
 	pWestcn "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/westcn"
+	pHttp01Builtin "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-http01/providers/builtin"
+	pHttp01Local "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-http01/providers/local"
+	pHttp01SSH "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-http01/providers/ssh"
+	pTlsAlpn01Standalone "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-tlsalpn01/providers/standalone"
 	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
 )
 
 type applicantProviderOptions struct {
 	Domains                 []string
 	ContactEmail            string
+	ChallengeType           domain.ACMEChallengeType
 	Provider                domain.ACMEDns01ProviderType
+	ProviderAccessId        string
 	ProviderAccessConfig    map[string]any
 	ProviderServiceConfig   map[string]any
 	CAProvider              domain.CAProviderType
@@ -59,6 +71,9 @@ type applicantProviderOptions struct {
 	CAProviderAccessConfig  map[string]any
 	CAProviderServiceConfig map[string]any
 	KeyAlgorithm            string
+	CSR                     *x509.CertificateRequest
+	PrivateKey              crypto.PrivateKey
+	PrivateKeyCreatedAt     time.Time
 	Nameservers             []string
 	DnsPropagationWait      int32
 	DnsPropagationTimeout   int32
@@ -67,6 +82,7 @@ type applicantProviderOptions struct {
 	DisableFollowCNAME      bool
 	ARIReplaceAcct          string
 	ARIReplaceCert          string
+	RateLimitMaxWait        int32
 }
 
 func createApplicantProvider(options *applicantProviderOptions) (challenge.Provider, error) {
@@ -75,6 +91,24 @@ func createApplicantProvider(options *applicantProviderOptions) (challenge.Provi
 	  NOTICE: If you add new constant, please keep ASCII order.
 	*/
 	switch options.Provider {
+	case domain.ACMEDns01ProviderTypeACMEDNS:
+		{
+			access := domain.AccessConfigForACMEDNS{}
+			if err := xmaps.Populate(options.ProviderAccessConfig, &access); err != nil {
+				return nil, fmt.Errorf("failed to populate provider access config: %w", err)
+			}
+
+			applicant, err := pACMEDNS.NewChallengeProvider(&pACMEDNS.ChallengeProviderConfig{
+				ServerUrl:             access.ServerUrl,
+				Username:              access.Username,
+				Password:              access.Password,
+				Subdomain:             access.Subdomain,
+				Credentials:           access.Credentials,
+				DnsPropagationTimeout: options.DnsPropagationTimeout,
+			})
+			return applicant, err
+		}
+
 	case domain.ACMEDns01ProviderTypeACMEHttpReq:
 		{
 			access := domain.AccessConfigForACMEHttpReq{}
@@ -346,6 +380,24 @@ func createApplicantProvider(options *applicantProviderOptions) (challenge.Provi
 			return applicant, err
 		}
 
+	case domain.ACMEDns01ProviderTypeExec:
+		{
+			access := domain.AccessConfigForExec{}
+			if err := xmaps.Populate(options.ProviderAccessConfig, &access); err != nil {
+				return nil, fmt.Errorf("failed to populate provider access config: %w", err)
+			}
+
+			applicant, err := pExec.NewChallengeProvider(&pExec.ChallengeProviderConfig{
+				ShellEnv:              access.ShellEnv,
+				Command:               access.Command,
+				Script:                access.Script,
+				Environment:           access.Environment,
+				DnsPropagationTimeout: options.DnsPropagationTimeout,
+				DnsTTL:                options.DnsTTL,
+			})
+			return applicant, err
+		}
+
 	case domain.ACMEDns01ProviderTypeGcore:
 		{
 			access := domain.AccessConfigForGcore{}
@@ -584,6 +636,24 @@ func createApplicantProvider(options *applicantProviderOptions) (challenge.Provi
 			return applicant, err
 		}
 
+	case domain.ACMEDns01ProviderTypeRFC2136:
+		{
+			access := domain.AccessConfigForRFC2136{}
+			if err := xmaps.Populate(options.ProviderAccessConfig, &access); err != nil {
+				return nil, fmt.Errorf("failed to populate provider access config: %w", err)
+			}
+
+			applicant, err := pRFC2136.NewChallengeProvider(&pRFC2136.ChallengeProviderConfig{
+				Nameserver:            access.Nameserver,
+				TsigKey:               access.TsigKey,
+				TsigAlgorithm:         access.TsigAlgorithm,
+				TsigSecret:            access.TsigSecret,
+				DnsPropagationTimeout: options.DnsPropagationTimeout,
+				DnsTTL:                options.DnsTTL,
+			})
+			return applicant, err
+		}
+
 	case domain.ACMEDns01ProviderTypeSpaceship:
 		{
 			access := domain.AccessConfigForSpaceship{}
@@ -699,3 +769,75 @@ func createApplicantProvider(options *applicantProviderOptions) (challenge.Provi
 
 	return nil, fmt.Errorf("unsupported applicant provider '%s'", string(options.Provider))
 }
+
+func createHttp01ApplicantProvider(options *applicantProviderOptions) (challenge.Provider, error) {
+	/*
+	  注意：如果追加新的常量值，请保持以 ASCII 排序。
+	  NOTICE: If you add new constant, please keep ASCII order.
+	*/
+	switch domain.ACMEHttp01ProviderType(options.Provider) {
+	case domain.ACMEHttp01ProviderTypeBuiltin:
+		{
+			applicant, err := pHttp01Builtin.NewChallengeProvider(&pHttp01Builtin.ChallengeProviderConfig{})
+			return applicant, err
+		}
+
+	case domain.ACMEHttp01ProviderTypeLocal:
+		{
+			applicant, err := pHttp01Local.NewChallengeProvider(&pHttp01Local.ChallengeProviderConfig{
+				WebRootPath: xmaps.GetString(options.ProviderServiceConfig, "webRootPath"),
+			})
+			return applicant, err
+		}
+
+	case domain.ACMEHttp01ProviderTypeSSH:
+		{
+			access := domain.AccessConfigForSSH{}
+			if err := xmaps.Populate(options.ProviderAccessConfig, &access); err != nil {
+				return nil, fmt.Errorf("failed to populate provider access config: %w", err)
+			}
+
+			jumpServers := make([]pHttp01SSH.JumpServerConfig, len(access.JumpServers))
+			for i, jumpServer := range access.JumpServers {
+				jumpServers[i] = pHttp01SSH.JumpServerConfig{
+					SshHost:               jumpServer.Host,
+					SshPort:               jumpServer.Port,
+					SshAuthMethod:         jumpServer.AuthMethod,
+					SshUsername:           jumpServer.Username,
+					SshPassword:           jumpServer.Password,
+					SshKey:                jumpServer.Key,
+					SshKeyPassphrase:      jumpServer.KeyPassphrase,
+					SshHostKeyFingerprint: jumpServer.HostKeyFingerprint,
+				}
+			}
+
+			applicant, err := pHttp01SSH.NewChallengeProvider(&pHttp01SSH.ChallengeProviderConfig{
+				SshHost:               access.Host,
+				SshPort:               access.Port,
+				SshAuthMethod:         access.AuthMethod,
+				SshUsername:           access.Username,
+				SshPassword:           access.Password,
+				SshKey:                access.Key,
+				SshKeyPassphrase:      access.KeyPassphrase,
+				SshHostKeyVerifyMode:  access.HostKeyVerifyMode,
+				SshHostKeyFingerprint: access.HostKeyFingerprint,
+				SshKnownHosts:         access.KnownHosts,
+				OnSshHostKeyTrusted:   newSSHHostKeyTrustedCallback(options.ProviderAccessId),
+				JumpServers:           jumpServers,
+				UseSCP:                xmaps.GetBool(options.ProviderServiceConfig, "useSCP"),
+				WebRootPath:           xmaps.GetString(options.ProviderServiceConfig, "webRootPath"),
+			})
+			return applicant, err
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported applicant provider '%s'", string(options.Provider))
+}
+
+func createTlsAlpn01ApplicantProvider(options *applicantProviderOptions) (challenge.Provider, error) {
+	applicant, err := pTlsAlpn01Standalone.NewChallengeProvider(&pTlsAlpn01Standalone.ChallengeProviderConfig{
+		ListenAddress: xmaps.GetString(options.ProviderServiceConfig, "listenAddress"),
+		ListenPort:    xmaps.GetInt32(options.ProviderServiceConfig, "listenPort"),
+	})
+	return applicant, err
+}
@@ -0,0 +1,426 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/repository"
+)
+
+const (
+	RateLimitCertificatesPerDomain = "certificatesPerDomain"
+	RateLimitDuplicateCertificates = "duplicateCertificates"
+	RateLimitFailedValidations     = "failedValidations"
+	RateLimitServerRateLimited     = "rateLimited"
+)
+
+// CA 的速率限制策略。
+// 参考 Let's Encrypt 的速率限制：https://letsencrypt.org/docs/rate-limits/
+type rateLimitPolicy struct {
+	// 每个注册域名在统计周期内可签发的证书数。
+	CertificatesPerDomain int
+	// 完全相同的域名集合在统计周期内可签发的证书数。
+	DuplicateCertificates int
+	// 证书数量的统计周期。
+	CertificatesWindow time.Duration
+	// 每个账户的每个主机名在统计周期内允许的验证失败次数。
+	FailedValidations int
+	// 验证失败次数的统计周期。
+	FailedValidationsWindow time.Duration
+}
+
+var caRateLimitPolicies = map[string]rateLimitPolicy{
+	caLetsEncrypt: {
+		CertificatesPerDomain:   50,
+		DuplicateCertificates:   5,
+		CertificatesWindow:      7 * 24 * time.Hour,
+		FailedValidations:       5,
+		FailedValidationsWindow: time.Hour,
+	},
+	caLetsEncryptStaging: {
+		CertificatesPerDomain:   30000,
+		DuplicateCertificates:   30000,
+		CertificatesWindow:      7 * 24 * time.Hour,
+		FailedValidations:       200,
+		FailedValidationsWindow: time.Hour,
+	},
+}
+
+// 服务端未指定 Retry-After 时，触发限流后的默认冷却时间。
+const rateLimitDefaultRetryAfter = time.Hour
+
+// 申请证书将超出或已超出 CA 速率限制时返回的错误。
+type RateLimitError struct {
+	CAProvider string
+	Limit      string
+	RetryAt    time.Time
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	msg := fmt.Sprintf("rate limit '%s' of ca '%s' exceeded", e.Limit, e.CAProvider)
+	if !e.RetryAt.IsZero() {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAt.UTC().Format(time.RFC3339))
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %s", e.Err.Error())
+	}
+	return msg
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// 速率限制状态持久化在数据库中，以免服务重启后丢失 CA 施加的限流或验证失败记录。
+// 同一进程内的读-改-写操作需要串行化。
+var rateLimitMtx sync.Mutex
+
+func getRateLimitAccountKey(options *applicantProviderOptions) string {
+	return fmt.Sprintf("%s#%s#%s", options.CAProvider, options.CAProviderAccessId, options.ContactEmail)
+}
+
+func getRateLimitState(ctx context.Context, accountKey string) (*domain.AcmeRateLimit, error) {
+	state, err := repository.NewAcmeRateLimitRepository().GetByKey(ctx, accountKey)
+	if err != nil {
+		if !domain.IsRecordNotFoundError(err) {
+			return nil, err
+		}
+
+		state = &domain.AcmeRateLimit{Key: accountKey}
+	}
+
+	if state.FailedValidations == nil {
+		state.FailedValidations = make(map[string][]time.Time)
+	}
+
+	return state, nil
+}
+
+// 申请证书前的速率限制预检。
+// 当申请将超出限制时，返回 [RateLimitError]，其中包含可以再次申请的时间。
+func checkRateLimits(ctx context.Context, options *applicantProviderOptions) error {
+	caProvider := string(options.CAProvider)
+	accountKey := getRateLimitAccountKey(options)
+	now := time.Now()
+
+	rateLimitMtx.Lock()
+	state, err := getRateLimitState(ctx, accountKey)
+	rateLimitMtx.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to get rate limit state: %w", err)
+	}
+
+	if now.Before(state.BlockedUntil) {
+		return &RateLimitError{CAProvider: caProvider, Limit: RateLimitServerRateLimited, RetryAt: state.BlockedUntil}
+	}
+
+	policy, ok := caRateLimitPolicies[caProvider]
+	if !ok {
+		return nil
+	}
+
+	// 验证失败次数
+	for _, name := range options.Domains {
+		failures := filterTimesAfter(state.FailedValidations[strings.TrimPrefix(name, "*.")], now.Add(-policy.FailedValidationsWindow))
+		if len(failures) >= policy.FailedValidations {
+			return &RateLimitError{CAProvider: caProvider, Limit: RateLimitFailedValidations, RetryAt: failures[len(failures)-policy.FailedValidations].Add(policy.FailedValidationsWindow)}
+		}
+	}
+
+	// 根据本地的证书签发记录统计证书数量
+	certificates, err := repository.NewCertificateRepository().ListByCAProviderCreatedAfter(ctx, caProvider, now.Add(-policy.CertificatesWindow))
+	if err != nil {
+		return fmt.Errorf("failed to get certificate history: %w", err)
+	}
+	slices.SortFunc(certificates, func(a, b *domain.Certificate) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	domains := normalizeRateLimitDomains(options.Domains)
+
+	duplicates := make([]time.Time, 0)
+	for _, certificate := range certificates {
+		if slices.Equal(normalizeRateLimitDomains(strings.Split(certificate.SubjectAltNames, ";")), domains) {
+			duplicates = append(duplicates, certificate.CreatedAt)
+		}
+	}
+	if len(duplicates) >= policy.DuplicateCertificates {
+		return &RateLimitError{CAProvider: caProvider, Limit: RateLimitDuplicateCertificates, RetryAt: duplicates[len(duplicates)-policy.DuplicateCertificates].Add(policy.CertificatesWindow)}
+	}
+
+	// 续期（域名集合与已签发的证书相同）不计入注册域名的证书数量限制
+	if len(duplicates) > 0 || options.ARIReplaceCert != "" {
+		return nil
+	}
+
+	for _, registeredDomain := range getRegisteredDomains(domains) {
+		issued := make([]time.Time, 0)
+		for _, certificate := range certificates {
+			if slices.Contains(getRegisteredDomains(strings.Split(certificate.SubjectAltNames, ";")), registeredDomain) {
+				issued = append(issued, certificate.CreatedAt)
+			}
+		}
+		if len(issued) >= policy.CertificatesPerDomain {
+			return &RateLimitError{CAProvider: caProvider, Limit: RateLimitCertificatesPerDomain, RetryAt: issued[len(issued)-policy.CertificatesPerDomain].Add(policy.CertificatesWindow)}
+		}
+	}
+
+	return nil
+}
+
+// 执行速率限制预检；超出限制且在允许的最长等待时间内可以恢复时，等待后再次检查。
+func waitForRateLimits(ctx context.Context, options *applicantProviderOptions) error {
+	deadline := time.Now().Add(time.Duration(options.RateLimitMaxWait) * time.Second)
+	for {
+		err := checkRateLimits(ctx, options)
+		if err == nil {
+			return nil
+		}
+
+		var rlErr *RateLimitError
+		if !errors.As(err, &rlErr) {
+			// 统计数据不可用时不阻止申请，由 CA 自行判断
+			return nil
+		}
+		if rlErr.RetryAt.IsZero() || rlErr.RetryAt.After(deadline) {
+			return rlErr
+		}
+
+		timer := time.NewTimer(time.Until(rlErr.RetryAt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// 记录申请失败时 CA 返回的限流或验证失败信息，并将限流错误包装为 [RateLimitError]。
+func handleRateLimitFailure(options *applicantProviderOptions, err error, retryAfter time.Duration) error {
+	var problem *acme.ProblemDetails
+	if !errors.As(err, &problem) {
+		return err
+	}
+
+	caProvider := string(options.CAProvider)
+	accountKey := getRateLimitAccountKey(options)
+	now := time.Now()
+
+	if problem.Type == acmeErrRateLimited {
+		if retryAfter <= 0 {
+			retryAfter = rateLimitDefaultRetryAfter
+		}
+
+		retryAt := now.Add(retryAfter)
+		if saveErr := updateRateLimitState(accountKey, func(state *domain.AcmeRateLimit) {
+			state.BlockedUntil = retryAt
+		}); saveErr != nil {
+			err = errors.Join(err, saveErr)
+		}
+
+		return &RateLimitError{CAProvider: caProvider, Limit: RateLimitServerRateLimited, RetryAt: retryAt, Err: err}
+	}
+
+	if failedDomains := getFailedValidationDomains(err, options.Domains); len(failedDomains) > 0 {
+		window := rateLimitDefaultRetryAfter
+		if policy, ok := caRateLimitPolicies[caProvider]; ok {
+			window = policy.FailedValidationsWindow
+		}
+
+		if saveErr := updateRateLimitState(accountKey, func(state *domain.AcmeRateLimit) {
+			// 清理已超出统计周期的记录，以免状态无限增长
+			for key, failures := range state.FailedValidations {
+				if failures = filterTimesAfter(failures, now.Add(-window)); len(failures) == 0 {
+					delete(state.FailedValidations, key)
+				} else {
+					state.FailedValidations[key] = failures
+				}
+			}
+
+			for _, name := range failedDomains {
+				key := strings.TrimPrefix(name, "*.")
+				state.FailedValidations[key] = append(state.FailedValidations[key], now)
+			}
+		}); saveErr != nil {
+			err = errors.Join(err, saveErr)
+		}
+	}
+
+	return err
+}
+
+func updateRateLimitState(accountKey string, update func(state *domain.AcmeRateLimit)) error {
+	rateLimitMtx.Lock()
+	defer rateLimitMtx.Unlock()
+
+	// 申请失败时上下文可能已被取消，但仍需记录状态
+	ctx := context.Background()
+
+	state, err := getRateLimitState(ctx, accountKey)
+	if err != nil {
+		return fmt.Errorf("failed to get rate limit state: %w", err)
+	}
+
+	update(state)
+	if _, err := repository.NewAcmeRateLimitRepository().Save(ctx, state); err != nil {
+		return fmt.Errorf("failed to save rate limit state: %w", err)
+	}
+
+	return nil
+}
+
+const acmeErrRateLimited = "urn:ietf:params:acme:error:rateLimited"
+
+var acmeValidationErrTypes = []string{
+	"urn:ietf:params:acme:error:caa",
+	"urn:ietf:params:acme:error:connection",
+	"urn:ietf:params:acme:error:dns",
+	"urn:ietf:params:acme:error:incorrectResponse",
+	"urn:ietf:params:acme:error:tls",
+	"urn:ietf:params:acme:error:unauthorized",
+}
+
+func isAcmeValidationError(err error) bool {
+	var problem *acme.ProblemDetails
+	return errors.As(err, &problem) && slices.Contains(acmeValidationErrTypes, problem.Type)
+}
+
+// 从 lego 返回的错误中找出验证失败的域名。
+// lego 以 "{domain}: {error}" 的形式合并各域名的错误，无法区分时视为所有域名均验证失败。
+func getFailedValidationDomains(err error, domains []string) []string {
+	if !isAcmeValidationError(err) {
+		return nil
+	}
+
+	failed := make([]string, 0)
+	var walk func(error)
+	walk = func(e error) {
+		switch x := e.(type) {
+		case interface{ Unwrap() []error }:
+			for _, child := range x.Unwrap() {
+				walk(child)
+			}
+			return
+		}
+
+		for _, name := range domains {
+			if strings.HasPrefix(e.Error(), name+": ") && isAcmeValidationError(e) && !slices.Contains(failed, name) {
+				failed = append(failed, name)
+				return
+			}
+		}
+
+		if next := errors.Unwrap(e); next != nil {
+			walk(next)
+		}
+	}
+	walk(err)
+
+	if len(failed) == 0 {
+		return domains
+	}
+	return failed
+}
+
+func normalizeRateLimitDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, name := range domains {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized
+}
+
+func getRegisteredDomains(domains []string) []string {
+	registered := make([]string, 0)
+	for _, name := range domains {
+		name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "*.")
+		if name == "" {
+			continue
+		}
+
+		etldPlusOne := name
+		if net.ParseIP(name) == nil {
+			if s, err := publicsuffix.EffectiveTLDPlusOne(name); err == nil {
+				etldPlusOne = s
+			}
+		}
+		if !slices.Contains(registered, etldPlusOne) {
+			registered = append(registered, etldPlusOne)
+		}
+	}
+	return registered
+}
+
+func filterTimesAfter(times []time.Time, since time.Time) []time.Time {
+	filtered := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		if t.After(since) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// 记录 CA 响应中的 Retry-After 头。
+// lego 在解析错误响应时会丢弃响应头，因此需要在传输层截获。
+type retryAfterTransport struct {
+	base http.RoundTripper
+
+	mtx        sync.Mutex
+	retryAfter time.Duration
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if d := parseRetryAfter(resp.Header.Get("Retry-After")); d > 0 {
+			t.mtx.Lock()
+			t.retryAfter = d
+			t.mtx.Unlock()
+		}
+	}
+
+	return resp, nil
+}
+
+func (t *retryAfterTransport) RetryAfter() time.Duration {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.retryAfter
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
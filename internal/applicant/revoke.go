@@ -0,0 +1,53 @@
+package applicant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+// 证书由本地 CA 签发，不支持吊销。
+var ErrRevocationNotSupported = domain.NewError(400, "revocation is not supported for certificates issued by the local ca")
+
+// 向签发证书的 CA 吊销证书。
+// 优先使用签发证书时的 ACME 账户签名请求；当账户不存在时，使用证书自身的私钥签名请求。
+//
+// 入参:
+//   - ctx: 上下文。
+//   - cert: 由工作流申请的证书。
+//   - reason: 吊销原因代码，参考 RFC 5280 §5.3.1。
+//
+// 出参:
+//   - err: 错误。
+func RevokeCertificate(ctx context.Context, cert *domain.Certificate, reason domain.CertificateRevocationReasonType) error {
+	if cert == nil {
+		return errors.New("certificate is nil")
+	}
+	if !reason.IsValid() {
+		return fmt.Errorf("invalid revocation reason code: %d", reason)
+	}
+	if cert.CAProvider == string(domain.CAProviderTypeLocalCA) {
+		return ErrRevocationNotSupported
+	}
+
+	client, err := newAcmeClientWithCertificateAccount(ctx, cert)
+	if err != nil {
+		if !errors.Is(err, domain.ErrRecordNotFound) {
+			return err
+		}
+
+		client, err = newAcmeClientWithCertificateKey(ctx, cert)
+		if err != nil {
+			return err
+		}
+	}
+
+	reasonCode := uint(reason)
+	if err := client.Certificate.RevokeWithReason([]byte(cert.Certificate), &reasonCode); err != nil {
+		return fmt.Errorf("failed to revoke certificate: %w", err)
+	}
+
+	return nil
+}
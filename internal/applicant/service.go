@@ -0,0 +1,444 @@
+package applicant
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/registration"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	pHttp01Builtin "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-http01/providers/builtin"
+	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
+	xslices "github.com/certimate-go/certimate/pkg/utils/slices"
+)
+
+type accessRepository interface {
+	GetById(ctx context.Context, id string) (*domain.Access, error)
+	Save(ctx context.Context, access *domain.Access) (*domain.Access, error)
+}
+
+type acmeAccountRepository interface {
+	List(ctx context.Context) ([]*domain.AcmeAccount, error)
+	GetById(ctx context.Context, id string) (*domain.AcmeAccount, error)
+	GetByCAAndEmail(ca, email string) (*domain.AcmeAccount, error)
+	Save(ctx context.Context, acmeAccount *domain.AcmeAccount) (*domain.AcmeAccount, error)
+	Delete(ctx context.Context, acmeAccount *domain.AcmeAccount) error
+}
+
+type ApplicantService struct {
+	accessRepo      accessRepository
+	acmeAccountRepo acmeAccountRepository
+}
+
+func NewApplicantService(accessRepo accessRepository, acmeAccountRepo acmeAccountRepository) *ApplicantService {
+	return &ApplicantService{
+		accessRepo:      accessRepo,
+		acmeAccountRepo: acmeAccountRepo,
+	}
+}
+
+func (s *ApplicantService) GetHttp01KeyAuthorization(ctx context.Context, req *dtos.ApplicantGetHttp01KeyAuthorizationReq) (*dtos.ApplicantGetHttp01KeyAuthorizationResp, error) {
+	if req.Token == "" {
+		return nil, domain.ErrInvalidParams
+	}
+
+	keyAuth, ok := pHttp01Builtin.GetKeyAuthorization(req.Token)
+	if !ok {
+		return nil, domain.ErrRecordNotFound
+	}
+
+	return &dtos.ApplicantGetHttp01KeyAuthorizationResp{
+		KeyAuthorization: keyAuth,
+	}, nil
+}
+
+func (s *ApplicantService) GenerateLocalCA(ctx context.Context, req *dtos.ApplicantGenerateLocalCAReq) (*dtos.ApplicantGetLocalCAChainResp, error) {
+	access, err := s.getLocalCAAccess(ctx, req.AccessId)
+	if err != nil {
+		return nil, err
+	}
+
+	caConfig, err := generateLocalCA(req.CommonName, req.Organization, req.KeyAlgorithm, req.RootValidityDays, req.IntermediateValidityDays)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveLocalCA(ctx, access, caConfig)
+}
+
+func (s *ApplicantService) ImportLocalCA(ctx context.Context, req *dtos.ApplicantImportLocalCAReq) (*dtos.ApplicantGetLocalCAChainResp, error) {
+	access, err := s.getLocalCAAccess(ctx, req.AccessId)
+	if err != nil {
+		return nil, err
+	}
+
+	caConfig := &domain.AccessConfigForLocalCA{
+		RootCertificate:         strings.TrimSpace(req.RootCertificate),
+		RootPrivateKey:          strings.TrimSpace(req.RootPrivateKey),
+		IntermediateCertificate: strings.TrimSpace(req.IntermediateCertificate),
+		IntermediatePrivateKey:  strings.TrimSpace(req.IntermediatePrivateKey),
+	}
+	if _, err := loadLocalCA(localCAConfigToMap(caConfig)); err != nil {
+		return nil, err
+	}
+
+	return s.saveLocalCA(ctx, access, caConfig)
+}
+
+func (s *ApplicantService) GetLocalCAChain(ctx context.Context, req *dtos.ApplicantGetLocalCAChainReq) (*dtos.ApplicantGetLocalCAChainResp, error) {
+	access, err := s.getLocalCAAccess(ctx, req.AccessId)
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := loadLocalCA(access.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildLocalCAChainResp(ca), nil
+}
+
+func (s *ApplicantService) ListAcmeAccounts(ctx context.Context, req *dtos.ApplicantListAcmeAccountsReq) (*dtos.ApplicantListAcmeAccountsResp, error) {
+	acmeAccounts, err := s.acmeAccountRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dtos.ApplicantListAcmeAccountsResp{
+		Accounts: make([]*dtos.ApplicantAcmeAccount, 0, len(acmeAccounts)),
+	}
+	for _, acmeAccount := range acmeAccounts {
+		resp.Accounts = append(resp.Accounts, buildAcmeAccountResp(acmeAccount))
+	}
+
+	return resp, nil
+}
+
+func (s *ApplicantService) ImportAcmeAccount(ctx context.Context, req *dtos.ApplicantImportAcmeAccountReq) (*dtos.ApplicantAcmeAccount, error) {
+	if req.CAProvider == "" || req.PrivateKey == "" {
+		return nil, domain.ErrInvalidParams
+	}
+	if req.CAProvider == string(domain.CAProviderTypeLocalCA) {
+		return nil, fmt.Errorf("ca provider '%s' does not use acme accounts", req.CAProvider)
+	}
+
+	ca := req.CAProvider
+	if ca == caCustom {
+		if req.CAProviderAccessId == "" {
+			return nil, domain.ErrInvalidParams
+		}
+		ca = fmt.Sprintf("%s#%s", ca, req.CAProviderAccessId)
+	}
+
+	privkey, err := parseAcmeAccountKey(req.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account private key: %w", err)
+	}
+
+	user := &acmeUser{
+		CA:      ca,
+		Email:   req.Email,
+		privkey: string(certcrypto.PEMEncode(privkey)),
+	}
+	config, err := newAcmeConfigWithUser(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := lego.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 通过私钥查找 CA 上已存在的账户
+	reg, err := client.Registration.ResolveAccountByKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve acme account: %w", err)
+	}
+
+	// 未指定邮箱时，使用账户的联系方式
+	if user.Email == "" {
+		for _, contact := range reg.Body.Contact {
+			if email, ok := strings.CutPrefix(contact, "mailto:"); ok {
+				user.Email = email
+				break
+			}
+		}
+	}
+	if user.Email == "" {
+		return nil, errors.New("the email of acme account is required")
+	}
+
+	if _, err := s.acmeAccountRepo.GetByCAAndEmail(user.CA, user.Email); err == nil {
+		return nil, fmt.Errorf("acme account with ca '%s' and email '%s' already exists", user.CA, user.Email)
+	}
+
+	acmeAccount, err := s.acmeAccountRepo.Save(ctx, &domain.AcmeAccount{
+		CA:       user.CA,
+		Email:    user.Email,
+		Key:      user.getPrivateKeyPEM(),
+		Resource: reg,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAcmeAccountResp(acmeAccount), nil
+}
+
+func (s *ApplicantService) RolloverAcmeAccountKey(ctx context.Context, req *dtos.ApplicantRolloverAcmeAccountKeyReq) (*dtos.ApplicantAcmeAccount, error) {
+	acmeAccount, user, err := s.getAcmeAccount(ctx, req.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := newAcmeConfigWithUser(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+
+	oldKey, err := parseAcmeAccountKey(acmeAccount.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account private key: %w", err)
+	}
+
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	newKeyPEM, err := xcert.ConvertECPrivateKeyToPEM(newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rolloverAcmeAccountKey(config, acmeAccount.Resource.URI, oldKey, newKey); err != nil {
+		return nil, err
+	}
+
+	acmeAccount.Key = newKeyPEM
+	if _, err := s.acmeAccountRepo.Save(ctx, acmeAccount); err != nil {
+		// CA 已更换为新密钥，保存失败时旧密钥将无法继续使用
+		return nil, fmt.Errorf("the acme account key has been changed, but failed to save the new key: %w", err)
+	}
+
+	return buildAcmeAccountResp(acmeAccount), nil
+}
+
+func (s *ApplicantService) DeactivateAcmeAccount(ctx context.Context, req *dtos.ApplicantDeactivateAcmeAccountReq) error {
+	acmeAccount, user, err := s.getAcmeAccount(ctx, req.AccountId)
+	if err != nil {
+		return err
+	}
+
+	config, err := newAcmeConfigWithUser(ctx, user, "")
+	if err != nil {
+		return err
+	}
+
+	client, err := lego.NewClient(config)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Registration.DeleteRegistration(); err != nil {
+		return fmt.Errorf("failed to deactivate acme account: %w", err)
+	}
+
+	// 账户停用后不可恢复，删除本地记录以便后续申请时重新注册
+	return s.acmeAccountRepo.Delete(ctx, acmeAccount)
+}
+
+func (s *ApplicantService) UpdateAcmeAccountContact(ctx context.Context, req *dtos.ApplicantUpdateAcmeAccountContactReq) (*dtos.ApplicantAcmeAccount, error) {
+	if req.Email == "" {
+		return nil, domain.ErrInvalidParams
+	}
+
+	acmeAccount, user, err := s.getAcmeAccount(ctx, req.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Email != acmeAccount.Email {
+		if _, err := s.acmeAccountRepo.GetByCAAndEmail(acmeAccount.CA, req.Email); err == nil {
+			return nil, fmt.Errorf("acme account with ca '%s' and email '%s' already exists", acmeAccount.CA, req.Email)
+		}
+	}
+
+	config, err := newAcmeConfigWithUser(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := lego.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Email = req.Email
+	reg, err := client.Registration.UpdateRegistration(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update acme account contact: %w", err)
+	}
+
+	acmeAccount.Email = req.Email
+	acmeAccount.Resource = reg
+	if _, err := s.acmeAccountRepo.Save(ctx, acmeAccount); err != nil {
+		return nil, err
+	}
+
+	return buildAcmeAccountResp(acmeAccount), nil
+}
+
+func (s *ApplicantService) TestDns01Provider(ctx context.Context, req *dtos.ApplicantTestDns01ProviderReq) (*dtos.ApplicantTestDns01ProviderResp, error) {
+	testDomain := strings.TrimPrefix(strings.TrimSpace(req.Domain), "*.")
+	if testDomain == "" {
+		return nil, domain.ErrInvalidParams
+	}
+
+	// 保存授权前进行测试时，以请求中尚未保存的授权配置为准
+	provider := req.Provider
+	accessConfig := req.AccessConfig
+	if req.AccessId != "" {
+		access, err := s.accessRepo.GetById(ctx, req.AccessId)
+		if err != nil {
+			return nil, err
+		}
+
+		if provider == "" {
+			provider = access.Provider
+		}
+		if accessConfig == nil {
+			accessConfig = access.Config
+		}
+	}
+	if provider == "" {
+		return nil, domain.ErrInvalidParams
+	}
+	if accessConfig == nil {
+		accessConfig = make(map[string]any)
+	}
+
+	options := &applicantProviderOptions{
+		Domains:               []string{testDomain},
+		ChallengeType:         domain.ACMEChallengeTypeDNS01,
+		Provider:              domain.ACMEDns01ProviderType(provider),
+		ProviderAccessConfig:  accessConfig,
+		ProviderServiceConfig: req.ProviderConfig,
+		Nameservers:           xslices.Filter(strings.Split(req.Nameservers, ";"), func(s string) bool { return s != "" }),
+		DnsPropagationTimeout: req.DnsPropagationTimeout,
+		DnsTTL:                req.DnsTTL,
+		DisableFollowCNAME:    req.DisableFollowCNAME,
+	}
+	return dryRunDns01Provider(ctx, options, testDomain)
+}
+
+func (s *ApplicantService) getAcmeAccount(ctx context.Context, accountId string) (*domain.AcmeAccount, *acmeUser, error) {
+	if accountId == "" {
+		return nil, nil, domain.ErrInvalidParams
+	}
+
+	acmeAccount, err := s.acmeAccountRepo.GetById(ctx, accountId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if acmeAccount.Resource == nil || acmeAccount.Resource.URI == "" {
+		return nil, nil, errors.New("acme account is not registered")
+	}
+
+	user := &acmeUser{
+		CA:           acmeAccount.CA,
+		Email:        acmeAccount.Email,
+		Registration: acmeAccount.Resource,
+		privkey:      acmeAccount.Key,
+	}
+
+	return acmeAccount, user, nil
+}
+
+func (s *ApplicantService) getLocalCAAccess(ctx context.Context, accessId string) (*domain.Access, error) {
+	if accessId == "" {
+		return nil, domain.ErrInvalidParams
+	}
+
+	access, err := s.accessRepo.GetById(ctx, accessId)
+	if err != nil {
+		return nil, err
+	}
+
+	if access.Provider != string(domain.AccessProviderTypeLocalCA) {
+		return nil, fmt.Errorf("access #%s is not a local ca", accessId)
+	}
+
+	return access, nil
+}
+
+func (s *ApplicantService) saveLocalCA(ctx context.Context, access *domain.Access, caConfig *domain.AccessConfigForLocalCA) (*dtos.ApplicantGetLocalCAChainResp, error) {
+	ca, err := loadLocalCA(localCAConfigToMap(caConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	if access.Config == nil {
+		access.Config = make(map[string]any)
+	}
+	for key, value := range localCAConfigToMap(caConfig) {
+		access.Config[key] = value
+	}
+	if _, err := s.accessRepo.Save(ctx, access); err != nil {
+		return nil, err
+	}
+
+	return buildLocalCAChainResp(ca), nil
+}
+
+func localCAConfigToMap(caConfig *domain.AccessConfigForLocalCA) map[string]any {
+	return map[string]any{
+		"rootCertificate":         caConfig.RootCertificate,
+		"rootPrivateKey":          caConfig.RootPrivateKey,
+		"intermediateCertificate": caConfig.IntermediateCertificate,
+		"intermediatePrivateKey":  caConfig.IntermediatePrivateKey,
+	}
+}
+
+func buildLocalCAChainResp(ca *localCA) *dtos.ApplicantGetLocalCAChainResp {
+	rootCertPEM, _ := xcert.ConvertCertificateToPEM(ca.root)
+
+	resp := &dtos.ApplicantGetLocalCAChainResp{
+		RootCertificate:  strings.TrimSpace(rootCertPEM),
+		CertificateChain: rootCertPEM,
+	}
+	if ca.intermediate != nil {
+		intermediateCertPEM, _ := xcert.ConvertCertificateToPEM(ca.intermediate)
+		resp.IntermediateCertificate = strings.TrimSpace(intermediateCertPEM)
+		resp.CertificateChain = intermediateCertPEM + rootCertPEM
+	}
+	resp.CertificateChain = strings.TrimSpace(resp.CertificateChain)
+
+	return resp
+}
+
+func buildAcmeAccountResp(acmeAccount *domain.AcmeAccount) *dtos.ApplicantAcmeAccount {
+	resp := &dtos.ApplicantAcmeAccount{
+		Id:        acmeAccount.Id,
+		CA:        acmeAccount.CA,
+		Email:     acmeAccount.Email,
+		CreatedAt: acmeAccount.CreatedAt,
+		UpdatedAt: acmeAccount.UpdatedAt,
+	}
+	if acmeAccount.Resource != nil {
+		resp.Url = acmeAccount.Resource.URI
+		resp.Status = acmeAccount.Resource.Body.Status
+	}
+
+	return resp
+}
@@ -0,0 +1,266 @@
+package certificate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/applicant"
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
+)
+
+var (
+	eventCheckInterval = time.Hour
+	eventRefireAfter   = 24 * time.Hour
+)
+
+func init() {
+	// 后台检查证书事件的时间间隔（单位：秒）
+	envCheckInterval := os.Getenv("CERTIMATE_CERTIFICATE_EVENT_CHECK_INTERVAL")
+	if n, err := strconv.Atoi(envCheckInterval); err == nil && n > 0 {
+		eventCheckInterval = time.Duration(n) * time.Second
+	}
+}
+
+type eventCertificateRepository interface {
+	ListLatestByWorkflowId(ctx context.Context, workflowId string) ([]*domain.Certificate, error)
+	Save(ctx context.Context, certificate *domain.Certificate) (*domain.Certificate, error)
+}
+
+type eventWorkflowRepository interface {
+	ListEnabledByTrigger(ctx context.Context, trigger domain.WorkflowTriggerType) ([]*domain.Workflow, error)
+}
+
+type eventWorkflowService interface {
+	StartRun(ctx context.Context, req *dtos.WorkflowStartRunReq) error
+}
+
+// 证书事件检查器。
+// 周期性地检查由事件触发的工作流所申请的证书，当证书剩余有效期低于阈值、
+// ARI 续期窗口已开启或 OCSP 报告已吊销时，触发对应的工作流。
+// 已触发的事件及 ARI 查询间隔均记录在证书上，服务重启后不会重复触发。
+type CertificateEventChecker struct {
+	certificateRepo eventCertificateRepository
+	workflowRepo    eventWorkflowRepository
+	workflowSvc     eventWorkflowService
+
+	once sync.Once
+	done chan struct{}
+}
+
+func NewCertificateEventChecker(certificateRepo eventCertificateRepository, workflowRepo eventWorkflowRepository, workflowSvc eventWorkflowService) *CertificateEventChecker {
+	return &CertificateEventChecker{
+		certificateRepo: certificateRepo,
+		workflowRepo:    workflowRepo,
+		workflowSvc:     workflowSvc,
+		done:            make(chan struct{}),
+	}
+}
+
+func (c *CertificateEventChecker) Start(ctx context.Context) {
+	c.once.Do(func() {
+		go func() {
+			defer close(c.done)
+
+			ticker := time.NewTicker(eventCheckInterval)
+			defer ticker.Stop()
+
+			for {
+				c.check(ctx)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	})
+}
+
+// 等待检查循环退出，调用前需先取消传入 [CertificateEventChecker.Start] 的上下文。
+func (c *CertificateEventChecker) Wait() {
+	// 尚未启动时，直接标记为已退出
+	c.once.Do(func() { close(c.done) })
+	<-c.done
+}
+
+func (c *CertificateEventChecker) check(ctx context.Context) {
+	workflows, err := c.workflowRepo.ListEnabledByTrigger(ctx, domain.WorkflowTriggerTypeEvent)
+	if err != nil {
+		app.GetLogger().Error("failed to get event-triggered workflows", "err", err)
+		return
+	}
+
+	for _, workflow := range workflows {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if workflow.Content == nil {
+			continue
+		}
+		if workflow.LastRunStatus == domain.WorkflowRunStatusTypePending || workflow.LastRunStatus == domain.WorkflowRunStatusTypeRunning {
+			continue
+		}
+
+		certificates, err := c.certificateRepo.ListLatestByWorkflowId(ctx, workflow.Id)
+		if err != nil {
+			app.GetLogger().Error("failed to get certificates of workflow", "workflowId", workflow.Id, "err", err)
+			continue
+		}
+
+		startCfg := workflow.Content.GetConfigForStart()
+		for _, certificate := range certificates {
+			event, payload := c.detectEvent(ctx, certificate, startCfg)
+			if event == "" {
+				continue
+			}
+
+			// 同一证书的同一事件在一段时间内仅触发一次，避免工作流执行失败时被反复触发
+			if certificate.LastEventFired == event && time.Since(certificate.LastEventFiredAt) < eventRefireAfter {
+				continue
+			}
+
+			app.GetLogger().Info(fmt.Sprintf("certificate event '%s' detected, ready to run workflow", event), "workflowId", workflow.Id, "certificateId", certificate.Id)
+			if err := c.workflowSvc.StartRun(ctx, &dtos.WorkflowStartRunReq{
+				WorkflowId: workflow.Id,
+				RunTrigger: domain.WorkflowTriggerTypeEvent,
+				RunPayload: payload,
+			}); err != nil {
+				app.GetLogger().Error("failed to start workflow run", "workflowId", workflow.Id, "err", err)
+				continue
+			}
+
+			certificate.LastEventFired = event
+			certificate.LastEventFiredAt = time.Now()
+			if _, err := c.certificateRepo.Save(ctx, certificate); err != nil {
+				app.GetLogger().Error("failed to save certificate", "certificateId", certificate.Id, "err", err)
+			}
+
+			// 每次检查时每个工作流至多触发一次执行
+			break
+		}
+	}
+}
+
+func (c *CertificateEventChecker) detectEvent(ctx context.Context, certificate *domain.Certificate, startCfg domain.WorkflowNodeConfigForStart) (domain.WorkflowTriggerEventType, map[string]any) {
+	daysLeft := int(math.Floor(time.Until(certificate.ExpireAt).Hours() / 24))
+	payload := map[string]any{
+		"certificateId":  certificate.Id,
+		"workflowNodeId": certificate.WorkflowNodeId,
+		"expireAt":       certificate.ExpireAt.UTC().Format(time.RFC3339),
+		"daysLeft":       daysLeft,
+	}
+
+	events := make(map[domain.WorkflowTriggerEventType]bool)
+	for _, event := range startCfg.TriggerEvents {
+		events[event] = true
+	}
+
+	if events[domain.WorkflowTriggerEventTypeCertificateRevoked] {
+		if revoked, reason := c.checkRevoked(ctx, certificate); revoked {
+			payload["event"] = string(domain.WorkflowTriggerEventTypeCertificateRevoked)
+			payload["revocationReason"] = reason
+			return domain.WorkflowTriggerEventTypeCertificateRevoked, payload
+		}
+	}
+
+	if events[domain.WorkflowTriggerEventTypeCertificateARI] {
+		if info := c.checkRenewalInfo(ctx, certificate); info != nil && !time.Now().Before(certificate.ACMERenewalTime) {
+			payload["event"] = string(domain.WorkflowTriggerEventTypeCertificateARI)
+			payload["renewalWindowStart"] = info.WindowStart.UTC().Format(time.RFC3339)
+			payload["renewalWindowEnd"] = info.WindowEnd.UTC().Format(time.RFC3339)
+			return domain.WorkflowTriggerEventTypeCertificateARI, payload
+		}
+	}
+
+	if events[domain.WorkflowTriggerEventTypeCertificateExpiring] {
+		if daysLeft <= int(startCfg.TriggerEventExpiryDays) {
+			payload["event"] = string(domain.WorkflowTriggerEventTypeCertificateExpiring)
+			return domain.WorkflowTriggerEventTypeCertificateExpiring, payload
+		}
+	}
+
+	return "", nil
+}
+
+func (c *CertificateEventChecker) checkRevoked(ctx context.Context, certificate *domain.Certificate) (bool, int) {
+	if certificate.IssuerCertificate == "" || time.Now().After(certificate.ExpireAt) {
+		return false, 0
+	}
+
+	certX509, err := xcert.ParseCertificateFromPEM(certificate.Certificate)
+	if err != nil || len(certX509.OCSPServer) == 0 {
+		return false, 0
+	}
+
+	issuerX509, err := xcert.ParseCertificateFromPEM(certificate.IssuerCertificate)
+	if err != nil {
+		return false, 0
+	}
+
+	status, err := xcert.QueryOCSPStatus(ctx, certX509, issuerX509)
+	if err != nil {
+		app.GetLogger().Warn("failed to query ocsp status", "certificateId", certificate.Id, "err", err)
+		return false, 0
+	}
+
+	return status.Status == ocsp.Revoked, status.RevocationReason
+}
+
+func (c *CertificateEventChecker) checkRenewalInfo(ctx context.Context, certificate *domain.Certificate) *applicant.RenewalInfo {
+	if certificate.ACMEAccountUrl == "" || certificate.ACMERenewed {
+		return nil
+	}
+
+	// 遵循 CA 建议的查询间隔
+	if time.Now().Before(certificate.ACMERenewalInfoRetryAt) {
+		return nil
+	}
+
+	info, err := applicant.GetRenewalInfo(ctx, certificate)
+	if err != nil {
+		if errors.Is(err, applicant.ErrNoARI) {
+			c.saveRenewalInfoRetryAt(ctx, certificate, time.Now().Add(eventRefireAfter))
+		} else {
+			app.GetLogger().Warn("failed to get acme renewal info", "certificateId", certificate.Id, "err", err)
+		}
+		return nil
+	}
+
+	if info.WindowStart.IsZero() || info.WindowEnd.Before(info.WindowStart) {
+		return nil
+	}
+
+	// 与申请节点共用续期窗口内选取的续期时间
+	changed := certificate.UpdateACMERenewalWindow(info.WindowStart, info.WindowEnd)
+	if info.RetryAfter > 0 {
+		certificate.ACMERenewalInfoRetryAt = time.Now().Add(info.RetryAfter)
+		changed = true
+	}
+	if changed {
+		if _, err := c.certificateRepo.Save(ctx, certificate); err != nil {
+			app.GetLogger().Error("failed to save certificate", "certificateId", certificate.Id, "err", err)
+		}
+	}
+
+	return info
+}
+
+func (c *CertificateEventChecker) saveRenewalInfoRetryAt(ctx context.Context, certificate *domain.Certificate, retryAt time.Time) {
+	certificate.ACMERenewalInfoRetryAt = retryAt
+	if _, err := c.certificateRepo.Save(ctx, certificate); err != nil {
+		app.GetLogger().Error("failed to save certificate", "certificateId", certificate.Id, "err", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"github.com/pocketbase/dbx"
 
 	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/applicant"
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/certimate-go/certimate/internal/domain/dtos"
 	"github.com/certimate-go/certimate/internal/notify"
@@ -29,6 +31,7 @@ const (
 type certificateRepository interface {
 	ListExpireSoon(ctx context.Context) ([]*domain.Certificate, error)
 	GetById(ctx context.Context, id string) (*domain.Certificate, error)
+	Save(ctx context.Context, certificate *domain.Certificate) (*domain.Certificate, error)
 	DeleteWhere(ctx context.Context, exprs ...dbx.Expression) (int, error)
 }
 
@@ -122,14 +125,17 @@ func (s *CertificateService) ArchiveFile(ctx context.Context, req *dtos.Certific
 				return nil, err
 			}
 
-			keyWriter, err := zipWriter.Create("privkey.pem")
-			if err != nil {
-				return nil, err
-			}
-
-			_, err = keyWriter.Write([]byte(certificate.PrivateKey))
-			if err != nil {
-				return nil, err
+			// 使用自定义 CSR 申请的证书不含私钥
+			if certificate.PrivateKey != "" {
+				keyWriter, err := zipWriter.Create("privkey.pem")
+				if err != nil {
+					return nil, err
+				}
+
+				_, err = keyWriter.Write([]byte(certificate.PrivateKey))
+				if err != nil {
+					return nil, err
+				}
 			}
 
 			err = zipWriter.Close()
@@ -145,6 +151,10 @@ func (s *CertificateService) ArchiveFile(ctx context.Context, req *dtos.Certific
 		{
 			const pfxPassword = "certimate"
 
+			if certificate.PrivateKey == "" {
+				return nil, errors.New("certificate has no private key, could not be archived as PFX")
+			}
+
 			certPFX, err := xcert.TransformCertificateFromPEMToPFX(certificate.Certificate, certificate.PrivateKey, pfxPassword)
 			if err != nil {
 				return nil, err
@@ -183,6 +193,10 @@ func (s *CertificateService) ArchiveFile(ctx context.Context, req *dtos.Certific
 		{
 			const jksPassword = "certimate"
 
+			if certificate.PrivateKey == "" {
+				return nil, errors.New("certificate has no private key, could not be archived as JKS")
+			}
+
 			certJKS, err := xcert.TransformCertificateFromPEMToJKS(certificate.Certificate, certificate.PrivateKey, jksPassword, jksPassword, jksPassword)
 			if err != nil {
 				return nil, err
@@ -247,6 +261,32 @@ func (s *CertificateService) ValidatePrivateKey(ctx context.Context, req *dtos.C
 	}, nil
 }
 
+func (s *CertificateService) RevokeCertificate(ctx context.Context, req *dtos.CertificateRevokeReq) error {
+	reason := domain.CertificateRevocationReasonType(req.Reason)
+	if !reason.IsValid() {
+		return domain.ErrInvalidParams
+	}
+
+	certificate, err := s.certificateRepo.GetById(ctx, req.CertificateId)
+	if err != nil {
+		return err
+	} else if certificate.IsRevoked() {
+		return domain.NewError(400, "certificate has already been revoked")
+	}
+
+	if err := applicant.RevokeCertificate(ctx, certificate, reason); err != nil {
+		return err
+	}
+
+	certificate.RevokedAt = time.Now()
+	certificate.RevocationReason = reason
+	if _, err := s.certificateRepo.Save(ctx, certificate); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func buildExpireSoonNotification(certificates []*domain.Certificate) *struct {
 	Subject string
 	Message string
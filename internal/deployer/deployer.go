@@ -4,12 +4,18 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/certimate-go/certimate/internal/repository"
 	"github.com/certimate-go/certimate/pkg/core"
 )
 
+// 不依赖私钥即可完成部署的提供商。
+var privkeyOptionalProviders = []domain.DeploymentProviderType{
+	domain.DeploymentProviderTypeWebhook,
+}
+
 type Deployer interface {
 	Deploy(ctx context.Context) error
 }
@@ -30,8 +36,15 @@ func NewWithWorkflowNode(config DeployerWithWorkflowNodeConfig) (Deployer, error
 	}
 
 	nodeCfg := config.Node.GetConfigForDeploy()
+
+	// 使用自定义 CSR 申请的证书不含私钥，此时仅允许不依赖私钥的部署目标
+	if config.PrivateKeyPEM == "" && !slices.Contains(privkeyOptionalProviders, domain.DeploymentProviderType(nodeCfg.Provider)) {
+		return nil, fmt.Errorf("the certificate has no private key (it may be issued from a custom csr), which is required by deployment provider '%s'", nodeCfg.Provider)
+	}
+
 	options := &deployerProviderOptions{
 		Provider:              domain.DeploymentProviderType(nodeCfg.Provider),
+		ProviderAccessId:      nodeCfg.ProviderAccessId,
 		ProviderAccessConfig:  make(map[string]any),
 		ProviderServiceConfig: nodeCfg.ProviderConfig,
 	}
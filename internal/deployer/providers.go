@@ -112,6 +112,7 @@ import (
 
 type deployerProviderOptions struct {
 	Provider              domain.DeploymentProviderType
+	ProviderAccessId      string
 	ProviderAccessConfig  map[string]any
 	ProviderServiceConfig map[string]any
 }
@@ -919,6 +920,11 @@ func createSSLDeployerProvider(options *deployerProviderOptions) (core.SSLDeploy
 				OutputServerCertPath:     xmaps.GetString(options.ProviderServiceConfig, "certPathForServerOnly"),
 				OutputIntermediaCertPath: xmaps.GetString(options.ProviderServiceConfig, "certPathForIntermediaOnly"),
 				OutputKeyPath:            xmaps.GetString(options.ProviderServiceConfig, "keyPath"),
+				OutputFileMode:           xmaps.GetString(options.ProviderServiceConfig, "fileMode"),
+				OutputFileOwner:          xmaps.GetString(options.ProviderServiceConfig, "fileOwner"),
+				OutputFileGroup:          xmaps.GetString(options.ProviderServiceConfig, "fileGroup"),
+				UseAtomicWrite:           xmaps.GetBool(options.ProviderServiceConfig, "atomicWrite"),
+				BackupAndRollback:        xmaps.GetBool(options.ProviderServiceConfig, "backupAndRollback"),
 				PfxPassword:              xmaps.GetString(options.ProviderServiceConfig, "pfxPassword"),
 				JksAlias:                 xmaps.GetString(options.ProviderServiceConfig, "jksAlias"),
 				JksKeypass:               xmaps.GetString(options.ProviderServiceConfig, "jksKeypass"),
@@ -1113,13 +1119,23 @@ func createSSLDeployerProvider(options *deployerProviderOptions) (core.SSLDeploy
 			jumpServers := make([]pSSH.JumpServerConfig, len(access.JumpServers))
 			for i, jumpServer := range access.JumpServers {
 				jumpServers[i] = pSSH.JumpServerConfig{
-					SshHost:          jumpServer.Host,
-					SshPort:          jumpServer.Port,
-					SshAuthMethod:    jumpServer.AuthMethod,
-					SshUsername:      jumpServer.Username,
-					SshPassword:      jumpServer.Password,
-					SshKey:           jumpServer.Key,
-					SshKeyPassphrase: jumpServer.KeyPassphrase,
+					SshHost:               jumpServer.Host,
+					SshPort:               jumpServer.Port,
+					SshAuthMethod:         jumpServer.AuthMethod,
+					SshUsername:           jumpServer.Username,
+					SshPassword:           jumpServer.Password,
+					SshKey:                jumpServer.Key,
+					SshKeyPassphrase:      jumpServer.KeyPassphrase,
+					SshHostKeyFingerprint: jumpServer.HostKeyFingerprint,
+				}
+			}
+
+			hosts := make([]pSSH.HostConfig, len(access.Hosts))
+			for i, host := range access.Hosts {
+				hosts[i] = pSSH.HostConfig{
+					SshHost:               host.Host,
+					SshPort:               host.Port,
+					SshHostKeyFingerprint: host.HostKeyFingerprint,
 				}
 			}
 
@@ -1131,7 +1147,15 @@ func createSSLDeployerProvider(options *deployerProviderOptions) (core.SSLDeploy
 				SshPassword:              access.Password,
 				SshKey:                   access.Key,
 				SshKeyPassphrase:         access.KeyPassphrase,
+				SshHostKeyVerifyMode:     access.HostKeyVerifyMode,
+				SshHostKeyFingerprint:    access.HostKeyFingerprint,
+				SshKnownHosts:            access.KnownHosts,
+				OnSshHostKeyTrusted:      newSSHHostKeyTrustedCallback(options.ProviderAccessId),
 				JumpServers:              jumpServers,
+				Hosts:                    hosts,
+				Parallelism:              xmaps.GetInt32(options.ProviderServiceConfig, "parallelism"),
+				FailurePolicy:            pSSH.FailurePolicyType(xmaps.GetString(options.ProviderServiceConfig, "failurePolicy")),
+				MinSuccessPercent:        xmaps.GetInt32(options.ProviderServiceConfig, "minSuccessPercent"),
 				UseSCP:                   xmaps.GetBool(options.ProviderServiceConfig, "useSCP"),
 				PreCommand:               xmaps.GetString(options.ProviderServiceConfig, "preCommand"),
 				PostCommand:              xmaps.GetString(options.ProviderServiceConfig, "postCommand"),
@@ -1140,6 +1164,11 @@ func createSSLDeployerProvider(options *deployerProviderOptions) (core.SSLDeploy
 				OutputServerCertPath:     xmaps.GetString(options.ProviderServiceConfig, "certPathForServerOnly"),
 				OutputIntermediaCertPath: xmaps.GetString(options.ProviderServiceConfig, "certPathForIntermediaOnly"),
 				OutputKeyPath:            xmaps.GetString(options.ProviderServiceConfig, "keyPath"),
+				OutputFileMode:           xmaps.GetString(options.ProviderServiceConfig, "fileMode"),
+				OutputFileOwner:          xmaps.GetString(options.ProviderServiceConfig, "fileOwner"),
+				OutputFileGroup:          xmaps.GetString(options.ProviderServiceConfig, "fileGroup"),
+				UseAtomicWrite:           xmaps.GetBool(options.ProviderServiceConfig, "atomicWrite"),
+				BackupAndRollback:        xmaps.GetBool(options.ProviderServiceConfig, "backupAndRollback"),
 				PfxPassword:              xmaps.GetString(options.ProviderServiceConfig, "pfxPassword"),
 				JksAlias:                 xmaps.GetString(options.ProviderServiceConfig, "jksAlias"),
 				JksKeypass:               xmaps.GetString(options.ProviderServiceConfig, "jksKeypass"),
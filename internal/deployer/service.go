@@ -0,0 +1,22 @@
+package deployer
+
+import (
+	"context"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+type accessRepository interface {
+	GetById(ctx context.Context, id string) (*domain.Access, error)
+	Update(ctx context.Context, id string, fn func(access *domain.Access) error) (*domain.Access, error)
+}
+
+type DeployerService struct {
+	accessRepo accessRepository
+}
+
+func NewDeployerService(accessRepo accessRepository) *DeployerService {
+	return &DeployerService{
+		accessRepo: accessRepo,
+	}
+}
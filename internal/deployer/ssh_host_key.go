@@ -0,0 +1,167 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/repository"
+	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
+	xssh "github.com/certimate-go/certimate/pkg/utils/ssh"
+)
+
+// 获取 SSH 授权中目标服务器（或主机组中的主机）或跳板机的主机公钥，并检查其是否已被信任。
+func (s *DeployerService) FetchSSHHostKey(ctx context.Context, req *dtos.DeployerFetchSSHHostKeyReq) (*dtos.DeployerFetchSSHHostKeyResp, error) {
+	if req.AccessId == "" {
+		return nil, domain.ErrInvalidParams
+	}
+
+	access, err := s.accessRepo.GetById(ctx, req.AccessId)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts, jumpServers, err := getSSHServerConfigs(access)
+	if err != nil {
+		return nil, err
+	}
+
+	var server xssh.ServerConfig
+	if req.JumpServerIndex > 0 {
+		if req.JumpServerIndex > len(jumpServers) {
+			return nil, domain.ErrInvalidParams
+		}
+
+		// 获取第 N 个跳板机的主机公钥时，需经由前 N-1 个跳板机发起连接
+		server = jumpServers[req.JumpServerIndex-1]
+		jumpServers = jumpServers[:req.JumpServerIndex-1]
+	} else {
+		if req.HostIndex < 0 || req.HostIndex >= len(hosts) {
+			return nil, domain.ErrInvalidParams
+		}
+
+		server = hosts[req.HostIndex]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	hostKey, err := xssh.FetchHostKey(ctx, server, jumpServers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.DeployerFetchSSHHostKeyResp{
+		Host:                server.Host,
+		Port:                server.Port,
+		KeyType:             hostKey.PublicKey.Type(),
+		Fingerprint:         hostKey.Fingerprint,
+		PublicKey:           strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey.PublicKey))),
+		Trusted:             hostKey.Trusted,
+		Mismatch:            hostKey.Mismatch,
+		TrustedFingerprints: strings.Join(server.HostKeyFingerprints, ";"),
+	}, nil
+}
+
+// 将指定的主机公钥指纹固定到 SSH 授权中目标服务器（或主机组中的主机）或跳板机上，将替换原有的指纹。
+func (s *DeployerService) ApproveSSHHostKey(ctx context.Context, req *dtos.DeployerApproveSSHHostKeyReq) error {
+	if req.AccessId == "" {
+		return domain.ErrInvalidParams
+	}
+	if !strings.HasPrefix(req.Fingerprint, "SHA256:") {
+		return errors.New("the host key fingerprint must be in SHA256 format")
+	}
+
+	_, err := s.accessRepo.Update(ctx, req.AccessId, func(access *domain.Access) error {
+		return access.SetSSHHostKeyFingerprint(req.HostIndex, req.JumpServerIndex, req.Fingerprint)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save ssh host key fingerprint: %w", err)
+	}
+
+	return nil
+}
+
+// 返回 SSH 授权中的目标服务器与跳板机连接配置。
+// 目标服务器数组中，第 0 个为主目标服务器，其后依次为主机组中的各主机。
+func getSSHServerConfigs(access *domain.Access) ([]xssh.ServerConfig, []xssh.ServerConfig, error) {
+	if access.Provider != string(domain.AccessProviderTypeSSH) {
+		return nil, nil, fmt.Errorf("access #%s is not a ssh access", access.Id)
+	}
+
+	accessConfig := domain.AccessConfigForSSH{}
+	if err := xmaps.Populate(access.Config, &accessConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to populate access config: %w", err)
+	}
+
+	host := xssh.ServerConfig{
+		Host:                accessConfig.Host,
+		Port:                accessConfig.Port,
+		AuthMethod:          accessConfig.AuthMethod,
+		Username:            accessConfig.Username,
+		Password:            accessConfig.Password,
+		Key:                 accessConfig.Key,
+		KeyPassphrase:       accessConfig.KeyPassphrase,
+		HostKeyVerifyMode:   accessConfig.HostKeyVerifyMode,
+		HostKeyFingerprints: strings.Split(accessConfig.HostKeyFingerprint, ";"),
+		KnownHosts:          accessConfig.KnownHosts,
+	}
+
+	hosts := make([]xssh.ServerConfig, 0, len(accessConfig.Hosts)+1)
+	hosts = append(hosts, host)
+	for _, groupHost := range accessConfig.Hosts {
+		server := host
+		server.Host = groupHost.Host
+		if groupHost.Port != 0 {
+			server.Port = groupHost.Port
+		}
+		server.HostKeyFingerprints = strings.Split(groupHost.HostKeyFingerprint, ";")
+		hosts = append(hosts, server)
+	}
+
+	jumpServers := make([]xssh.ServerConfig, 0, len(accessConfig.JumpServers))
+	for _, jumpServer := range accessConfig.JumpServers {
+		jumpServers = append(jumpServers, xssh.ServerConfig{
+			Host:                jumpServer.Host,
+			Port:                jumpServer.Port,
+			AuthMethod:          jumpServer.AuthMethod,
+			Username:            jumpServer.Username,
+			Password:            jumpServer.Password,
+			Key:                 jumpServer.Key,
+			KeyPassphrase:       jumpServer.KeyPassphrase,
+			HostKeyVerifyMode:   accessConfig.HostKeyVerifyMode,
+			HostKeyFingerprints: strings.Split(jumpServer.HostKeyFingerprint, ";"),
+			KnownHosts:          accessConfig.KnownHosts,
+		})
+	}
+
+	return hosts, jumpServers, nil
+}
+
+// 返回首次连接信任 SSH 主机公钥后，将其指纹固定到授权中的回调。
+// 未关联授权时返回 nil，此时无法固定主机公钥，校验模式 "tofu" 将按 "strict" 处理。
+func newSSHHostKeyTrustedCallback(accessId string) func(hostIndex int, jumpServerIndex int, fingerprint string) error {
+	if accessId == "" {
+		return nil
+	}
+
+	return func(hostIndex int, jumpServerIndex int, fingerprint string) error {
+		accessRepo := repository.NewAccessRepository()
+
+		// 多个部署节点或多主机部署可能同时固定同一授权中的主机公钥，需在仓储层串行化
+		_, err := accessRepo.Update(context.Background(), accessId, func(access *domain.Access) error {
+			return access.SetSSHHostKeyFingerprint(hostIndex, jumpServerIndex, fingerprint)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save ssh host key fingerprint to access #%s: %w", accessId, err)
+		}
+
+		return nil
+	}
+}
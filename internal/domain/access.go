@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -15,6 +16,56 @@ type Access struct {
 	DeletedAt *time.Time     `json:"deleted" db:"deleted"`
 }
 
+// 设置 SSH 授权中已信任的主机公钥指纹。
+// hostIndex 为 0 时表示主目标服务器，否则表示主机组中的第 hostIndex 个主机；
+// jumpServerIndex 为 0 时表示目标服务器本身，否则表示第 jumpServerIndex 个跳板机（此时忽略 hostIndex）。
+func (a *Access) SetSSHHostKeyFingerprint(hostIndex int, jumpServerIndex int, fingerprint string) error {
+	if a.Provider != string(AccessProviderTypeSSH) {
+		return fmt.Errorf("access #%s is not a ssh access", a.Id)
+	}
+
+	if a.Config == nil {
+		a.Config = make(map[string]any)
+	}
+
+	if jumpServerIndex > 0 {
+		jumpServer, err := getAccessConfigListItem(a.Config, "jumpServers", jumpServerIndex)
+		if err != nil {
+			return fmt.Errorf("jump server [%d] of access #%s: %w", jumpServerIndex, a.Id, err)
+		}
+
+		jumpServer["hostKeyFingerprint"] = fingerprint
+		return nil
+	}
+
+	if hostIndex > 0 {
+		host, err := getAccessConfigListItem(a.Config, "hosts", hostIndex)
+		if err != nil {
+			return fmt.Errorf("host [%d] of access #%s: %w", hostIndex, a.Id, err)
+		}
+
+		host["hostKeyFingerprint"] = fingerprint
+		return nil
+	}
+
+	a.Config["hostKeyFingerprint"] = fingerprint
+	return nil
+}
+
+func getAccessConfigListItem(config map[string]any, key string, index int) (map[string]any, error) {
+	list, _ := config[key].([]any)
+	if index < 1 || index > len(list) {
+		return nil, fmt.Errorf("item does not exist")
+	}
+
+	item, ok := list[index-1].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("item is invalid")
+	}
+
+	return item, nil
+}
+
 type AccessConfigFor1Panel struct {
 	ServerUrl                string `json:"serverUrl"`
 	ApiVersion               string `json:"apiVersion"`
@@ -23,9 +74,21 @@ type AccessConfigFor1Panel struct {
 }
 
 type AccessConfigForACMECA struct {
-	Endpoint   string `json:"endpoint"`
-	EabKid     string `json:"eabKid,omitempty"`
-	EabHmacKey string `json:"eabHmacKey,omitempty"`
+	Endpoint          string `json:"endpoint"`
+	EabKid            string `json:"eabKid,omitempty"`
+	EabHmacKey        string `json:"eabHmacKey,omitempty"`
+	CACertificate     string `json:"caCertificate,omitempty"`
+	ClientCertificate string `json:"clientCertificate,omitempty"`
+	ClientPrivateKey  string `json:"clientPrivateKey,omitempty"`
+}
+
+type AccessConfigForACMEDNS struct {
+	ServerUrl   string `json:"serverUrl"`
+	Username    string `json:"username,omitempty"`
+	Password    string `json:"password,omitempty"`
+	Subdomain   string `json:"subdomain,omitempty"`
+	FullDomain  string `json:"fullDomain,omitempty"`
+	Credentials string `json:"credentials,omitempty"`
 }
 
 type AccessConfigForACMEHttpReq struct {
@@ -177,6 +240,13 @@ type AccessConfigForEmail struct {
 	DefaultReceiverAddress string `json:"defaultReceiverAddress,omitempty"`
 }
 
+type AccessConfigForExec struct {
+	ShellEnv    string            `json:"shellEnv,omitempty"`
+	Command     string            `json:"command,omitempty"`
+	Script      string            `json:"script,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+}
+
 type AccessConfigForFlexCDN struct {
 	ServerUrl                string `json:"serverUrl"`
 	ApiRole                  string `json:"apiRole"`
@@ -250,6 +320,13 @@ type AccessConfigForLeCDN struct {
 	AllowInsecureConnections bool   `json:"allowInsecureConnections,omitempty"`
 }
 
+type AccessConfigForLocalCA struct {
+	RootCertificate         string `json:"rootCertificate"`
+	RootPrivateKey          string `json:"rootPrivateKey,omitempty"`
+	IntermediateCertificate string `json:"intermediateCertificate,omitempty"`
+	IntermediatePrivateKey  string `json:"intermediatePrivateKey,omitempty"`
+}
+
 type AccessConfigForMattermost struct {
 	ServerUrl        string `json:"serverUrl"`
 	Username         string `json:"username"`
@@ -319,6 +396,13 @@ type AccessConfigForRatPanel struct {
 	AllowInsecureConnections bool   `json:"allowInsecureConnections,omitempty"`
 }
 
+type AccessConfigForRFC2136 struct {
+	Nameserver    string `json:"nameserver"`
+	TsigKey       string `json:"tsigKey,omitempty"`
+	TsigAlgorithm string `json:"tsigAlgorithm,omitempty"`
+	TsigSecret    string `json:"tsigSecret,omitempty"`
+}
+
 type AccessConfigForSafeLine struct {
 	ServerUrl                string `json:"serverUrl"`
 	ApiToken                 string `json:"apiToken"`
@@ -336,21 +420,30 @@ type AccessConfigForSpaceship struct {
 }
 
 type AccessConfigForSSH struct {
-	Host          string `json:"host"`
-	Port          int32  `json:"port"`
-	AuthMethod    string `json:"authMethod,omitempty"`
-	Username      string `json:"username,omitempty"`
-	Password      string `json:"password,omitempty"`
-	Key           string `json:"key,omitempty"`
-	KeyPassphrase string `json:"keyPassphrase,omitempty"`
-	JumpServers   []struct {
-		Host          string `json:"host"`
-		Port          int32  `json:"port"`
-		AuthMethod    string `json:"authMethod,omitempty"`
-		Username      string `json:"username,omitempty"`
-		Password      string `json:"password,omitempty"`
-		Key           string `json:"key,omitempty"`
-		KeyPassphrase string `json:"keyPassphrase,omitempty"`
+	Host               string `json:"host"`
+	Port               int32  `json:"port"`
+	AuthMethod         string `json:"authMethod,omitempty"`
+	Username           string `json:"username,omitempty"`
+	Password           string `json:"password,omitempty"`
+	Key                string `json:"key,omitempty"`
+	KeyPassphrase      string `json:"keyPassphrase,omitempty"`
+	HostKeyVerifyMode  string `json:"hostKeyVerifyMode,omitempty"`
+	HostKeyFingerprint string `json:"hostKeyFingerprint,omitempty"`
+	KnownHosts         string `json:"knownHosts,omitempty"`
+	Hosts              []struct {
+		Host               string `json:"host"`
+		Port               int32  `json:"port,omitempty"`
+		HostKeyFingerprint string `json:"hostKeyFingerprint,omitempty"`
+	} `json:"hosts,omitempty"`
+	JumpServers []struct {
+		Host               string `json:"host"`
+		Port               int32  `json:"port"`
+		AuthMethod         string `json:"authMethod,omitempty"`
+		Username           string `json:"username,omitempty"`
+		Password           string `json:"password,omitempty"`
+		Key                string `json:"key,omitempty"`
+		KeyPassphrase      string `json:"keyPassphrase,omitempty"`
+		HostKeyFingerprint string `json:"hostKeyFingerprint,omitempty"`
 	} `json:"jumpServers,omitempty"`
 }
 
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"time"
+)
+
+const CollectionNameAcmeRateLimit = "acme_rate_limits"
+
+// 表示某个 ACME 账户在 CA 侧的速率限制状态，服务重启后仍然有效。
+type AcmeRateLimit struct {
+	Meta
+	Key               string                 `json:"key" db:"key"`                             // 账户标识，形如 "${CAProvider}#${CAProviderAccessId}#${ContactEmail}"
+	BlockedUntil      time.Time              `json:"blockedUntil" db:"blockedUntil"`           // CA 返回限流错误后，在此时间之前不再发起申请
+	FailedValidations map[string][]time.Time `json:"failedValidations" db:"failedValidations"` // 各主机名最近的验证失败时间
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
+	"math/rand/v2"
 	"strings"
 	"time"
 
@@ -15,25 +16,35 @@ const CollectionNameCertificate = "certificate"
 
 type Certificate struct {
 	Meta
-	Source            CertificateSourceType       `json:"source" db:"source"`
-	SubjectAltNames   string                      `json:"subjectAltNames" db:"subjectAltNames"`
-	SerialNumber      string                      `json:"serialNumber" db:"serialNumber"`
-	Certificate       string                      `json:"certificate" db:"certificate"`
-	PrivateKey        string                      `json:"privateKey" db:"privateKey"`
-	IssuerOrg         string                      `json:"issuerOrg" db:"issuerOrg"`
-	IssuerCertificate string                      `json:"issuerCertificate" db:"issuerCertificate"`
-	KeyAlgorithm      CertificateKeyAlgorithmType `json:"keyAlgorithm" db:"keyAlgorithm"`
-	EffectAt          time.Time                   `json:"effectAt" db:"effectAt"`
-	ExpireAt          time.Time                   `json:"expireAt" db:"expireAt"`
-	ACMEAccountUrl    string                      `json:"acmeAccountUrl" db:"acmeAccountUrl"`
-	ACMECertUrl       string                      `json:"acmeCertUrl" db:"acmeCertUrl"`
-	ACMECertStableUrl string                      `json:"acmeCertStableUrl" db:"acmeCertStableUrl"`
-	ACMERenewed       bool                        `json:"acmeRenewed" db:"acmeRenewed"`
-	WorkflowId        string                      `json:"workflowId" db:"workflowId"`
-	WorkflowNodeId    string                      `json:"workflowNodeId" db:"workflowNodeId"`
-	WorkflowRunId     string                      `json:"workflowRunId" db:"workflowRunId"`
-	WorkflowOutputId  string                      `json:"workflowOutputId" db:"workflowOutputId"`
-	DeletedAt         *time.Time                  `json:"deleted" db:"deleted"`
+	Source                 CertificateSourceType           `json:"source" db:"source"`
+	SubjectAltNames        string                          `json:"subjectAltNames" db:"subjectAltNames"`
+	SerialNumber           string                          `json:"serialNumber" db:"serialNumber"`
+	Certificate            string                          `json:"certificate" db:"certificate"`
+	PrivateKey             string                          `json:"privateKey" db:"privateKey"`
+	IssuerOrg              string                          `json:"issuerOrg" db:"issuerOrg"`
+	IssuerCertificate      string                          `json:"issuerCertificate" db:"issuerCertificate"`
+	KeyAlgorithm           CertificateKeyAlgorithmType     `json:"keyAlgorithm" db:"keyAlgorithm"`
+	EffectAt               time.Time                       `json:"effectAt" db:"effectAt"`
+	ExpireAt               time.Time                       `json:"expireAt" db:"expireAt"`
+	ACMEAccountUrl         string                          `json:"acmeAccountUrl" db:"acmeAccountUrl"`
+	ACMECertUrl            string                          `json:"acmeCertUrl" db:"acmeCertUrl"`
+	ACMECertStableUrl      string                          `json:"acmeCertStableUrl" db:"acmeCertStableUrl"`
+	ACMERenewed            bool                            `json:"acmeRenewed" db:"acmeRenewed"`
+	ACMERenewalWindowStart time.Time                       `json:"acmeRenewalWindowStart" db:"acmeRenewalWindowStart"`
+	ACMERenewalWindowEnd   time.Time                       `json:"acmeRenewalWindowEnd" db:"acmeRenewalWindowEnd"`
+	ACMERenewalTime        time.Time                       `json:"acmeRenewalTime" db:"acmeRenewalTime"`
+	RevokedAt              time.Time                       `json:"revokedAt" db:"revokedAt"`
+	RevocationReason       CertificateRevocationReasonType `json:"revocationReason" db:"revocationReason"`
+	KeyCreatedAt           time.Time                       `json:"keyCreatedAt" db:"keyCreatedAt"`
+	CAProvider             string                          `json:"caProvider" db:"caProvider"`
+	ACMERenewalInfoRetryAt time.Time                       `json:"acmeRenewalInfoRetryAt" db:"acmeRenewalInfoRetryAt"`
+	LastEventFired         WorkflowTriggerEventType        `json:"lastEventFired" db:"lastEventFired"`
+	LastEventFiredAt       time.Time                       `json:"lastEventFiredAt" db:"lastEventFiredAt"`
+	WorkflowId             string                          `json:"workflowId" db:"workflowId"`
+	WorkflowNodeId         string                          `json:"workflowNodeId" db:"workflowNodeId"`
+	WorkflowRunId          string                          `json:"workflowRunId" db:"workflowRunId"`
+	WorkflowOutputId       string                          `json:"workflowOutputId" db:"workflowOutputId"`
+	DeletedAt              *time.Time                      `json:"deleted" db:"deleted"`
 }
 
 func (c *Certificate) PopulateFromX509(certX509 *x509.Certificate) *Certificate {
@@ -102,6 +113,36 @@ func (c *Certificate) PopulateFromX509(certX509 *x509.Certificate) *Certificate
 	return c
 }
 
+func (c *Certificate) IsRevoked() bool {
+	return !c.RevokedAt.IsZero()
+}
+
+// 更新 ARI 续期窗口。
+// 按照 RFC 9773 的建议，在续期窗口内均匀地随机选取一个时间点作为续期时间；
+// 同一续期窗口内仅选取一次，以免每次检查时重新选取导致续期时间偏向窗口起点。
+//
+// 入参:
+//   - windowStart: 续期窗口开始时间。
+//   - windowEnd: 续期窗口结束时间。
+//
+// 出参:
+//   - 是否有变更。
+func (c *Certificate) UpdateACMERenewalWindow(windowStart, windowEnd time.Time) bool {
+	if c.ACMERenewalWindowStart.Equal(windowStart) && c.ACMERenewalWindowEnd.Equal(windowEnd) &&
+		!c.ACMERenewalTime.Before(windowStart) && c.ACMERenewalTime.Before(windowEnd) {
+		return false
+	}
+
+	c.ACMERenewalWindowStart = windowStart
+	c.ACMERenewalWindowEnd = windowEnd
+	c.ACMERenewalTime = windowStart
+	if span := windowEnd.Sub(windowStart); span > 0 {
+		c.ACMERenewalTime = windowStart.Add(time.Duration(rand.Int64N(int64(span))))
+	}
+
+	return true
+}
+
 func (c *Certificate) PopulateFromPEM(certPEM, privkeyPEM string) *Certificate {
 	c.Certificate = certPEM
 	c.PrivateKey = privkeyPEM
@@ -135,3 +176,23 @@ const (
 	CertificateKeyAlgorithmTypeEC384   = CertificateKeyAlgorithmType("EC384")
 	CertificateKeyAlgorithmTypeEC512   = CertificateKeyAlgorithmType("EC512")
 )
+
+type CertificateRevocationReasonType int32
+
+const (
+	CertificateRevocationReasonTypeUnspecified          = CertificateRevocationReasonType(0)
+	CertificateRevocationReasonTypeKeyCompromise        = CertificateRevocationReasonType(1)
+	CertificateRevocationReasonTypeCACompromise         = CertificateRevocationReasonType(2)
+	CertificateRevocationReasonTypeAffiliationChanged   = CertificateRevocationReasonType(3)
+	CertificateRevocationReasonTypeSuperseded           = CertificateRevocationReasonType(4)
+	CertificateRevocationReasonTypeCessationOfOperation = CertificateRevocationReasonType(5)
+	CertificateRevocationReasonTypeCertificateHold      = CertificateRevocationReasonType(6)
+	CertificateRevocationReasonTypeRemoveFromCRL        = CertificateRevocationReasonType(8)
+	CertificateRevocationReasonTypePrivilegeWithdrawn   = CertificateRevocationReasonType(9)
+	CertificateRevocationReasonTypeAACompromise         = CertificateRevocationReasonType(10)
+)
+
+// 判断是否为 RFC 5280 §5.3.1 中定义的吊销原因代码（其中 7 未被使用）。
+func (t CertificateRevocationReasonType) IsValid() bool {
+	return t >= 0 && t <= 10 && t != 7
+}
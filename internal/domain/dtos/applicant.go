@@ -0,0 +1,125 @@
+package dtos
+
+import "time"
+
+type ApplicantGetHttp01KeyAuthorizationReq struct {
+	Token string `json:"-"`
+}
+
+type ApplicantGetHttp01KeyAuthorizationResp struct {
+	KeyAuthorization string `json:"keyAuthorization"`
+}
+
+type ApplicantGenerateLocalCAReq struct {
+	AccessId                 string `json:"-"`
+	CommonName               string `json:"commonName"`
+	Organization             string `json:"organization"`
+	KeyAlgorithm             string `json:"keyAlgorithm"`
+	RootValidityDays         int32  `json:"rootValidityDays"`
+	IntermediateValidityDays int32  `json:"intermediateValidityDays"`
+}
+
+type ApplicantImportLocalCAReq struct {
+	AccessId                string `json:"-"`
+	RootCertificate         string `json:"rootCertificate"`
+	RootPrivateKey          string `json:"rootPrivateKey"`
+	IntermediateCertificate string `json:"intermediateCertificate"`
+	IntermediatePrivateKey  string `json:"intermediatePrivateKey"`
+}
+
+type ApplicantGetLocalCAChainReq struct {
+	AccessId string `json:"-"`
+}
+
+type ApplicantGetLocalCAChainResp struct {
+	RootCertificate         string `json:"rootCertificate"`
+	IntermediateCertificate string `json:"intermediateCertificate,omitempty"`
+	CertificateChain        string `json:"certificateChain"`
+}
+
+type ApplicantAcmeAccount struct {
+	Id        string    `json:"id"`
+	CA        string    `json:"ca"`
+	Email     string    `json:"email"`
+	Url       string    `json:"url"`
+	Status    string    `json:"status,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ApplicantListAcmeAccountsReq struct{}
+
+type ApplicantListAcmeAccountsResp struct {
+	Accounts []*ApplicantAcmeAccount `json:"accounts"`
+}
+
+type ApplicantImportAcmeAccountReq struct {
+	CAProvider         string `json:"caProvider"`
+	CAProviderAccessId string `json:"caProviderAccessId"`
+	Email              string `json:"email"`
+	PrivateKey         string `json:"privateKey"`
+}
+
+type ApplicantRolloverAcmeAccountKeyReq struct {
+	AccountId string `json:"-"`
+}
+
+type ApplicantDeactivateAcmeAccountReq struct {
+	AccountId string `json:"-"`
+}
+
+type ApplicantUpdateAcmeAccountContactReq struct {
+	AccountId string `json:"-"`
+	Email     string `json:"email"`
+}
+
+type ApplicantTestDns01ProviderReq struct {
+	Provider              string         `json:"provider"`
+	AccessId              string         `json:"accessId,omitempty"`
+	AccessConfig          map[string]any `json:"accessConfig,omitempty"`
+	ProviderConfig        map[string]any `json:"providerConfig,omitempty"`
+	Domain                string         `json:"domain"`
+	Nameservers           string         `json:"nameservers,omitempty"`
+	DnsPropagationTimeout int32          `json:"dnsPropagationTimeout,omitempty"`
+	DnsTTL                int32          `json:"dnsTTL,omitempty"`
+	DisableFollowCNAME    bool           `json:"disableFollowCNAME,omitempty"`
+}
+
+type ApplicantTestDns01ProviderResp struct {
+	Success             bool                                          `json:"success"`
+	Error               string                                        `json:"error,omitempty"`
+	Domain              string                                        `json:"domain"`
+	FQDN                string                                        `json:"fqdn"`
+	Value               string                                        `json:"value"`
+	Nameservers         []*ApplicantTestDns01ProviderNameserverResult `json:"nameservers"`
+	PresentDuration     int64                                         `json:"presentDuration"`
+	PropagationDuration int64                                         `json:"propagationDuration"`
+	CleanUpDuration     int64                                         `json:"cleanUpDuration"`
+	TotalDuration       int64                                         `json:"totalDuration"`
+}
+
+type ApplicantTestDns01ProviderNameserverResult struct {
+	Nameserver string `json:"nameserver"`
+	Visible    bool   `json:"visible"`
+	Duration   int64  `json:"duration,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type ApplicantGetAcmeDnsCNAMERecordsReq struct {
+	AccessId  string   `json:"-"`
+	Domains   string   `json:"domains"`
+	Register  bool     `json:"register,omitempty"`
+	AllowFrom []string `json:"allowFrom,omitempty"`
+}
+
+type ApplicantGetAcmeDnsCNAMERecordsResp struct {
+	Records []*ApplicantAcmeDnsCNAMERecord `json:"records"`
+}
+
+type ApplicantAcmeDnsCNAMERecord struct {
+	Domain     string `json:"domain"`
+	Host       string `json:"host"`
+	Type       string `json:"type"`
+	Value      string `json:"value,omitempty"`
+	Registered bool   `json:"registered"`
+}
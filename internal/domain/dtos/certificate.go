@@ -26,3 +26,8 @@ type CertificateValidatePrivateKeyReq struct {
 type CertificateValidatePrivateKeyResp struct {
 	IsValid bool `json:"isValid"`
 }
+
+type CertificateRevokeReq struct {
+	CertificateId string `json:"-"`
+	Reason        int32  `json:"reason"`
+}
@@ -0,0 +1,25 @@
+package dtos
+
+type DeployerFetchSSHHostKeyReq struct {
+	AccessId        string `json:"-"`
+	HostIndex       int    `json:"hostIndex"`
+	JumpServerIndex int    `json:"jumpServerIndex"`
+}
+
+type DeployerFetchSSHHostKeyResp struct {
+	Host                string `json:"host"`
+	Port                int32  `json:"port"`
+	KeyType             string `json:"keyType"`
+	Fingerprint         string `json:"fingerprint"`
+	PublicKey           string `json:"publicKey"`
+	Trusted             bool   `json:"trusted"`
+	Mismatch            bool   `json:"mismatch"`
+	TrustedFingerprints string `json:"trustedFingerprints,omitempty"`
+}
+
+type DeployerApproveSSHHostKeyReq struct {
+	AccessId        string `json:"-"`
+	HostIndex       int    `json:"hostIndex"`
+	JumpServerIndex int    `json:"jumpServerIndex"`
+	Fingerprint     string `json:"fingerprint"`
+}
@@ -5,6 +5,18 @@ import "github.com/certimate-go/certimate/internal/domain"
 type WorkflowStartRunReq struct {
 	WorkflowId string                     `json:"-"`
 	RunTrigger domain.WorkflowTriggerType `json:"trigger"`
+	RunPayload map[string]any             `json:"-"`
+}
+
+type WorkflowTriggerWebhookReq struct {
+	WorkflowId string `json:"-"`
+	Token      string `json:"-"`
+	Signature  string `json:"-"`
+	Body       []byte `json:"-"`
+}
+
+type WorkflowTriggerWebhookResp struct {
+	RunId string `json:"runId"`
 }
 
 type WorkflowCancelRunReq struct {
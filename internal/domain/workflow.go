@@ -34,6 +34,7 @@ const (
 	WorkflowNodeTypeUpload              = WorkflowNodeType("upload")
 	WorkflowNodeTypeMonitor             = WorkflowNodeType("monitor")
 	WorkflowNodeTypeDeploy              = WorkflowNodeType("deploy")
+	WorkflowNodeTypeRevoke              = WorkflowNodeType("revoke")
 	WorkflowNodeTypeNotify              = WorkflowNodeType("notify")
 	WorkflowNodeTypeBranch              = WorkflowNodeType("branch")
 	WorkflowNodeTypeCondition           = WorkflowNodeType("condition")
@@ -45,8 +46,18 @@ const (
 type WorkflowTriggerType string
 
 const (
-	WorkflowTriggerTypeAuto   = WorkflowTriggerType("auto")
-	WorkflowTriggerTypeManual = WorkflowTriggerType("manual")
+	WorkflowTriggerTypeAuto    = WorkflowTriggerType("auto")
+	WorkflowTriggerTypeManual  = WorkflowTriggerType("manual")
+	WorkflowTriggerTypeWebhook = WorkflowTriggerType("webhook")
+	WorkflowTriggerTypeEvent   = WorkflowTriggerType("event")
+)
+
+type WorkflowTriggerEventType string
+
+const (
+	WorkflowTriggerEventTypeCertificateExpiring = WorkflowTriggerEventType("certificate.expiring")
+	WorkflowTriggerEventTypeCertificateARI      = WorkflowTriggerEventType("certificate.ari")
+	WorkflowTriggerEventTypeCertificateRevoked  = WorkflowTriggerEventType("certificate.revoked")
 )
 
 type WorkflowNode struct {
@@ -64,25 +75,66 @@ type WorkflowNode struct {
 	Validated bool `json:"validated"`
 }
 
+type WorkflowNodeRetryPolicy struct {
+	MaxAttempts     int32                            `json:"maxAttempts,omitempty"`     // 最大尝试次数，包含首次执行（零值时不重试）
+	InitialDelay    int32                            `json:"initialDelay,omitempty"`    // 首次重试前的等待时间，单位为秒（零值时默认值 5）
+	MaxDelay        int32                            `json:"maxDelay,omitempty"`        // 单次重试前的最长等待时间，单位为秒（零值时默认值 300）
+	BackoffFactor   float64                          `json:"backoffFactor,omitempty"`   // 指数退避因子（零值时默认值 2）
+	RetryableErrors []WorkflowNodeRetryableErrorType `json:"retryableErrors,omitempty"` // 可重试的错误类别（零值时任意错误均可重试）
+}
+
+type WorkflowNodeRetryableErrorType string
+
+const (
+	WorkflowNodeRetryableErrorTypeNetwork   = WorkflowNodeRetryableErrorType("network")
+	WorkflowNodeRetryableErrorTypeTimeout   = WorkflowNodeRetryableErrorType("timeout")
+	WorkflowNodeRetryableErrorTypeRateLimit = WorkflowNodeRetryableErrorType("rate_limit")
+	WorkflowNodeRetryableErrorTypeServer    = WorkflowNodeRetryableErrorType("server")
+)
+
+type WorkflowNodeConfigForStart struct {
+	Trigger        string `json:"trigger"`                  // 触发方式
+	TriggerCron    string `json:"triggerCron,omitempty"`    // 定时触发的 cron 表达式
+	MaxParallelism int32  `json:"maxParallelism,omitempty"` // 并行分支的最大并发数（零值时默认值 4）
+	RunTimeout     int32  `json:"runTimeout,omitempty"`     // 单次执行的最长时间，单位：秒（零值时使用全局配置）
+	WebhookToken   string `json:"webhookToken,omitempty"`   // Webhook 触发时 URL 中的密钥令牌
+	WebhookSecret  string `json:"webhookSecret,omitempty"`  // Webhook 触发时 HMAC-SHA256 签名的密钥（零值时不校验签名）
+
+	TriggerEvents          []WorkflowTriggerEventType `json:"triggerEvents,omitempty"`          // 事件触发时监听的事件类型（零值时监听全部事件）
+	TriggerEventExpiryDays int32                      `json:"triggerEventExpiryDays,omitempty"` // 事件触发时证书剩余有效天数的阈值（零值时默认值 30）
+}
+
 type WorkflowNodeConfigForApply struct {
-	Domains               string         `json:"domains"`                         // 域名列表，以半角分号分隔
-	ContactEmail          string         `json:"contactEmail"`                    // 联系邮箱
-	ChallengeType         string         `json:"challengeType"`                   // TODO: 验证方式。目前仅支持 dns-01
-	Provider              string         `json:"provider"`                        // DNS 提供商
-	ProviderAccessId      string         `json:"providerAccessId"`                // DNS 提供商授权记录 ID
-	ProviderConfig        map[string]any `json:"providerConfig,omitempty"`        // DNS 提供商额外配置
-	CAProvider            string         `json:"caProvider,omitempty"`            // CA 提供商（零值时使用全局配置）
-	CAProviderAccessId    string         `json:"caProviderAccessId,omitempty"`    // CA 提供商授权记录 ID
-	CAProviderConfig      map[string]any `json:"caProviderConfig,omitempty"`      // CA 提供商额外配置
-	KeyAlgorithm          string         `json:"keyAlgorithm,omitempty"`          // 证书算法
-	ACMEProfile           string         `json:"acmeProfile,omitempty"`           // ACME Profiles Extension
-	Nameservers           string         `json:"nameservers,omitempty"`           // DNS 服务器列表，以半角分号分隔
-	DnsPropagationWait    int32          `json:"dnsPropagationWait,omitempty"`    // DNS 传播等待时间，等同于 lego 的 `--dns-propagation-wait` 参数
-	DnsPropagationTimeout int32          `json:"dnsPropagationTimeout,omitempty"` // DNS 传播检查超时时间（零值时使用提供商的默认值）
-	DnsTTL                int32          `json:"dnsTTL,omitempty"`                // DNS 解析记录 TTL（零值时使用提供商的默认值）
-	DisableFollowCNAME    bool           `json:"disableFollowCNAME,omitempty"`    // 是否关闭 CNAME 跟随
-	DisableARI            bool           `json:"disableARI,omitempty"`            // 是否关闭 ARI
-	SkipBeforeExpiryDays  int32          `json:"skipBeforeExpiryDays,omitempty"`  // 证书到期前多少天前跳过续期（零值时默认值 30）
+	Domains               string                                 `json:"domains"`                         // 域名列表，以半角分号分隔
+	ContactEmail          string                                 `json:"contactEmail"`                    // 联系邮箱
+	ChallengeType         string                                 `json:"challengeType"`                   // 质询方式（零值时默认值 dns-01）
+	Provider              string                                 `json:"provider"`                        // 质询提供商（dns-01 时为 DNS 提供商，http-01 时为 HTTP 提供商，tls-alpn-01 时无需指定）
+	ProviderAccessId      string                                 `json:"providerAccessId"`                // 质询提供商授权记录 ID
+	ProviderConfig        map[string]any                         `json:"providerConfig,omitempty"`        // 质询提供商额外配置
+	CAProvider            string                                 `json:"caProvider,omitempty"`            // CA 提供商（零值时使用全局配置）
+	CAProviderAccessId    string                                 `json:"caProviderAccessId,omitempty"`    // CA 提供商授权记录 ID
+	CAProviderConfig      map[string]any                         `json:"caProviderConfig,omitempty"`      // CA 提供商额外配置
+	CAProviderFallbacks   []WorkflowNodeConfigForApplyCAFallback `json:"caProviderFallbacks,omitempty"`   // 备用 CA 提供商列表（主 CA 限流、服务端错误或超时时按顺序依次尝试）
+	KeyAlgorithm          string                                 `json:"keyAlgorithm,omitempty"`          // 证书算法
+	CSR                   string                                 `json:"csr,omitempty"`                   // 自定义证书签名请求 PEM 内容（非零值时域名与公钥均以 CSR 为准，忽略 [KeyAlgorithm]）
+	PrivateKey            string                                 `json:"privateKey,omitempty"`            // 固定使用的私钥 PEM 内容（非零值时每次续期均复用此私钥，忽略 [KeyAlgorithm]）
+	ReuseKey              bool                                   `json:"reuseKey,omitempty"`              // 续期时是否复用上次证书的私钥（仅在证书算法和域名均未变更时生效）
+	ReuseKeyMaxAge        int32                                  `json:"reuseKeyMaxAge,omitempty"`        // 复用私钥的最长使用天数，超过后强制生成新私钥（零值时不限制）
+	ACMEProfile           string                                 `json:"acmeProfile,omitempty"`           // ACME Profiles Extension
+	Nameservers           string                                 `json:"nameservers,omitempty"`           // DNS 服务器列表，以半角分号分隔
+	DnsPropagationWait    int32                                  `json:"dnsPropagationWait,omitempty"`    // DNS 传播等待时间，等同于 lego 的 `--dns-propagation-wait` 参数
+	DnsPropagationTimeout int32                                  `json:"dnsPropagationTimeout,omitempty"` // DNS 传播检查超时时间（零值时使用提供商的默认值）
+	DnsTTL                int32                                  `json:"dnsTTL,omitempty"`                // DNS 解析记录 TTL（零值时使用提供商的默认值）
+	DisableFollowCNAME    bool                                   `json:"disableFollowCNAME,omitempty"`    // 是否关闭 CNAME 跟随
+	DisableARI            bool                                   `json:"disableARI,omitempty"`            // 是否关闭 ARI
+	SkipBeforeExpiryDays  int32                                  `json:"skipBeforeExpiryDays,omitempty"`  // 证书到期前多少天前跳过续期（零值时默认值 30）
+	RateLimitMaxWait      int32                                  `json:"rateLimitMaxWait,omitempty"`      // 将超出 CA 速率限制时的最长等待时间，单位：秒（零值时不等待，直接失败）
+}
+
+type WorkflowNodeConfigForApplyCAFallback struct {
+	CAProvider         string         `json:"caProvider"`                   // CA 提供商
+	CAProviderAccessId string         `json:"caProviderAccessId,omitempty"` // CA 提供商授权记录 ID
+	CAProviderConfig   map[string]any `json:"caProviderConfig,omitempty"`   // CA 提供商额外配置
 }
 
 type WorkflowNodeConfigForUpload struct {
@@ -106,6 +158,11 @@ type WorkflowNodeConfigForDeploy struct {
 	SkipOnLastSucceeded bool           `json:"skipOnLastSucceeded"`        // 上次部署成功时是否跳过
 }
 
+type WorkflowNodeConfigForRevoke struct {
+	Certificate string `json:"certificate"`      // 前序节点输出的证书，形如“${NodeId}#certificate”
+	Reason      int32  `json:"reason,omitempty"` // 吊销原因代码，参考 RFC 5280 §5.3.1（零值时默认值 0，即 unspecified）
+}
+
 type WorkflowNodeConfigForNotify struct {
 	Channel              string         `json:"channel,omitempty"`        // Deprecated: v0.4.x 将废弃
 	Provider             string         `json:"provider"`                 // 通知提供商
@@ -120,18 +177,129 @@ type WorkflowNodeConfigForCondition struct {
 	Expression expr.Expr `json:"expression"` // 条件表达式
 }
 
+func (n *WorkflowNode) GetTimeout() time.Duration {
+	// 零值时使用节点类型的默认值
+	timeout := xmaps.GetInt32(n.Config, "timeout")
+	if timeout > 0 {
+		return time.Duration(timeout) * time.Second
+	}
+
+	switch n.Type {
+	case WorkflowNodeTypeApply:
+		return 30 * time.Minute
+	case WorkflowNodeTypeDeploy:
+		return 10 * time.Minute
+	case WorkflowNodeTypeUpload, WorkflowNodeTypeMonitor, WorkflowNodeTypeRevoke, WorkflowNodeTypeNotify:
+		return 1 * time.Minute
+	}
+
+	return 0
+}
+
+func (n *WorkflowNode) GetRetryPolicy() WorkflowNodeRetryPolicy {
+	policy := WorkflowNodeRetryPolicy{}
+	if err := xmaps.Populate(xmaps.GetKVMapAny(n.Config, "retryPolicy"), &policy); err != nil {
+		policy = WorkflowNodeRetryPolicy{}
+	}
+
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.InitialDelay <= 0 {
+		policy.InitialDelay = 5
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 300
+	}
+	if policy.BackoffFactor < 1 {
+		policy.BackoffFactor = 2
+	}
+
+	return policy
+}
+
+func (n *WorkflowNode) GetConfigForStart() WorkflowNodeConfigForStart {
+	return WorkflowNodeConfigForStart{
+		Trigger:        xmaps.GetString(n.Config, "trigger"),
+		TriggerCron:    xmaps.GetString(n.Config, "triggerCron"),
+		MaxParallelism: xmaps.GetOrDefaultInt32(n.Config, "maxParallelism", 4),
+		RunTimeout:     xmaps.GetInt32(n.Config, "runTimeout"),
+		WebhookToken:   xmaps.GetString(n.Config, "webhookToken"),
+		WebhookSecret:  xmaps.GetString(n.Config, "webhookSecret"),
+
+		TriggerEvents:          n.getTriggerEvents(),
+		TriggerEventExpiryDays: xmaps.GetOrDefaultInt32(n.Config, "triggerEventExpiryDays", 30),
+	}
+}
+
+func (n *WorkflowNode) getTriggerEvents() []WorkflowTriggerEventType {
+	events := make([]WorkflowTriggerEventType, 0)
+	switch list := n.Config["triggerEvents"].(type) {
+	case []string:
+		for _, item := range list {
+			if item != "" {
+				events = append(events, WorkflowTriggerEventType(item))
+			}
+		}
+	case []any:
+		for _, item := range list {
+			if s, ok := item.(string); ok && s != "" {
+				events = append(events, WorkflowTriggerEventType(s))
+			}
+		}
+	}
+
+	if len(events) == 0 {
+		events = []WorkflowTriggerEventType{
+			WorkflowTriggerEventTypeCertificateExpiring,
+			WorkflowTriggerEventTypeCertificateARI,
+			WorkflowTriggerEventTypeCertificateRevoked,
+		}
+	}
+
+	return events
+}
+
+func (n *WorkflowNode) getCAProviderFallbacks() []WorkflowNodeConfigForApplyCAFallback {
+	fallbacks := make([]WorkflowNodeConfigForApplyCAFallback, 0)
+	if list, ok := n.Config["caProviderFallbacks"].([]any); ok {
+		for _, item := range list {
+			dict, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			fallback := WorkflowNodeConfigForApplyCAFallback{
+				CAProvider:         xmaps.GetString(dict, "caProvider"),
+				CAProviderAccessId: xmaps.GetString(dict, "caProviderAccessId"),
+				CAProviderConfig:   xmaps.GetKVMapAny(dict, "caProviderConfig"),
+			}
+			if fallback.CAProvider != "" {
+				fallbacks = append(fallbacks, fallback)
+			}
+		}
+	}
+
+	return fallbacks
+}
+
 func (n *WorkflowNode) GetConfigForApply() WorkflowNodeConfigForApply {
 	return WorkflowNodeConfigForApply{
 		Domains:               xmaps.GetString(n.Config, "domains"),
 		ContactEmail:          xmaps.GetString(n.Config, "contactEmail"),
-		ChallengeType:         xmaps.GetString(n.Config, "challengeType"),
+		ChallengeType:         xmaps.GetOrDefaultString(n.Config, "challengeType", string(ACMEChallengeTypeDNS01)),
 		Provider:              xmaps.GetString(n.Config, "provider"),
 		ProviderAccessId:      xmaps.GetString(n.Config, "providerAccessId"),
 		ProviderConfig:        xmaps.GetKVMapAny(n.Config, "providerConfig"),
 		CAProvider:            xmaps.GetString(n.Config, "caProvider"),
 		CAProviderAccessId:    xmaps.GetString(n.Config, "caProviderAccessId"),
 		CAProviderConfig:      xmaps.GetKVMapAny(n.Config, "caProviderConfig"),
+		CAProviderFallbacks:   n.getCAProviderFallbacks(),
 		KeyAlgorithm:          xmaps.GetOrDefaultString(n.Config, "keyAlgorithm", string(CertificateKeyAlgorithmTypeRSA2048)),
+		CSR:                   xmaps.GetString(n.Config, "csr"),
+		PrivateKey:            xmaps.GetString(n.Config, "privateKey"),
+		ReuseKey:              xmaps.GetBool(n.Config, "reuseKey"),
+		ReuseKeyMaxAge:        xmaps.GetInt32(n.Config, "reuseKeyMaxAge"),
 		ACMEProfile:           xmaps.GetString(n.Config, "acmeProfile"),
 		Nameservers:           xmaps.GetString(n.Config, "nameservers"),
 		DnsPropagationWait:    xmaps.GetInt32(n.Config, "dnsPropagationWait"),
@@ -140,6 +308,7 @@ func (n *WorkflowNode) GetConfigForApply() WorkflowNodeConfigForApply {
 		DisableFollowCNAME:    xmaps.GetBool(n.Config, "disableFollowCNAME"),
 		DisableARI:            xmaps.GetBool(n.Config, "disableARI"),
 		SkipBeforeExpiryDays:  xmaps.GetOrDefaultInt32(n.Config, "skipBeforeExpiryDays", 30),
+		RateLimitMaxWait:      xmaps.GetInt32(n.Config, "rateLimitMaxWait"),
 	}
 }
 
@@ -171,6 +340,13 @@ func (n *WorkflowNode) GetConfigForDeploy() WorkflowNodeConfigForDeploy {
 	}
 }
 
+func (n *WorkflowNode) GetConfigForRevoke() WorkflowNodeConfigForRevoke {
+	return WorkflowNodeConfigForRevoke{
+		Certificate: xmaps.GetString(n.Config, "certificate"),
+		Reason:      xmaps.GetInt32(n.Config, "reason"),
+	}
+}
+
 func (n *WorkflowNode) GetConfigForNotify() WorkflowNodeConfigForNotify {
 	return WorkflowNodeConfigForNotify{
 		Channel:              xmaps.GetString(n.Config, "channel"),
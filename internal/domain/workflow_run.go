@@ -15,6 +15,7 @@ type WorkflowRun struct {
 	EndedAt    time.Time             `json:"endedAt" db:"endedAt"`
 	Detail     *WorkflowNode         `json:"detail" db:"detail"`
 	Error      string                `json:"error" db:"error"`
+	Payload    map[string]any        `json:"payload,omitempty" db:"payload"`
 }
 
 type WorkflowRunStatusType string
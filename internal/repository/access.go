@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 
 	"github.com/certimate-go/certimate/internal/app"
@@ -34,6 +36,86 @@ func (r *AccessRepository) GetById(ctx context.Context, id string) (*domain.Acce
 	return r.castRecordToModel(record)
 }
 
+func (r *AccessRepository) ListByProvider(ctx context.Context, provider string) ([]*domain.Access, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameAccess,
+		"provider={:provider} && deleted=null",
+		"-created",
+		0, 0,
+		dbx.Params{"provider": provider},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	accesses := make([]*domain.Access, 0)
+	for _, record := range records {
+		access, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		accesses = append(accesses, access)
+	}
+
+	return accesses, nil
+}
+
+func (r *AccessRepository) Save(ctx context.Context, access *domain.Access) (*domain.Access, error) {
+	collection, err := app.GetApp().FindCollectionByNameOrId(domain.CollectionNameAccess)
+	if err != nil {
+		return access, err
+	}
+
+	var record *core.Record
+	if access.Id == "" {
+		record = core.NewRecord(collection)
+	} else {
+		record, err = app.GetApp().FindRecordById(collection, access.Id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return access, domain.ErrRecordNotFound
+			}
+			return access, err
+		}
+	}
+
+	record.Set("name", access.Name)
+	record.Set("provider", access.Provider)
+	record.Set("config", access.Config)
+	record.Set("reserve", access.Reserve)
+	if err := app.GetApp().Save(record); err != nil {
+		return access, err
+	}
+
+	access.Id = record.Id
+	access.CreatedAt = record.GetDateTime("created").Time()
+	access.UpdatedAt = record.GetDateTime("updated").Time()
+	return access, nil
+}
+
+// 对同一授权的“读取-修改-保存”操作加锁，以免并发修改时相互覆盖。
+var accessUpdateMtxs sync.Map
+
+// 读取指定授权，交由 fn 修改后保存。
+// 同一进程内对同一授权的并发调用将被串行化，fn 总是基于最新保存的数据进行修改。
+func (r *AccessRepository) Update(ctx context.Context, id string, fn func(access *domain.Access) error) (*domain.Access, error) {
+	mtx, _ := accessUpdateMtxs.LoadOrStore(id, &sync.Mutex{})
+	mtx.(*sync.Mutex).Lock()
+	defer mtx.(*sync.Mutex).Unlock()
+
+	access, err := r.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fn(access); err != nil {
+		return access, err
+	}
+
+	return r.Save(ctx, access)
+}
+
 func (r *AccessRepository) castRecordToModel(record *core.Record) (*domain.Access, error) {
 	if record == nil {
 		return nil, fmt.Errorf("record is nil")
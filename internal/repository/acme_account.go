@@ -23,6 +23,42 @@ func NewAcmeAccountRepository() *AcmeAccountRepository {
 
 var g singleflight.Group
 
+func (r *AcmeAccountRepository) List(ctx context.Context) ([]*domain.AcmeAccount, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameAcmeAccount,
+		"",
+		"-created",
+		0, 0,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	acmeAccounts := make([]*domain.AcmeAccount, 0)
+	for _, record := range records {
+		acmeAccount, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		acmeAccounts = append(acmeAccounts, acmeAccount)
+	}
+
+	return acmeAccounts, nil
+}
+
+func (r *AcmeAccountRepository) GetById(ctx context.Context, id string) (*domain.AcmeAccount, error) {
+	record, err := app.GetApp().FindRecordById(domain.CollectionNameAcmeAccount, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return r.castRecordToModel(record)
+}
+
 func (r *AcmeAccountRepository) GetByCAAndEmail(ca, email string) (*domain.AcmeAccount, error) {
 	resp, err, _ := g.Do(fmt.Sprintf("acme_account_%s_%s", ca, email), func() (interface{}, error) {
 		resp, err := app.GetApp().FindFirstRecordByFilter(
@@ -51,6 +87,22 @@ func (r *AcmeAccountRepository) GetByCAAndEmail(ca, email string) (*domain.AcmeA
 	return r.castRecordToModel(record)
 }
 
+func (r *AcmeAccountRepository) GetByUrl(ctx context.Context, url string) (*domain.AcmeAccount, error) {
+	record, err := app.GetApp().FindFirstRecordByFilter(
+		domain.CollectionNameAcmeAccount,
+		"resource.uri={:uri}",
+		dbx.Params{"uri": url},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return r.castRecordToModel(record)
+}
+
 func (r *AcmeAccountRepository) Save(ctx context.Context, acmeAccount *domain.AcmeAccount) (*domain.AcmeAccount, error) {
 	collection, err := app.GetApp().FindCollectionByNameOrId(domain.CollectionNameAcmeAccount)
 	if err != nil {
@@ -84,6 +136,18 @@ func (r *AcmeAccountRepository) Save(ctx context.Context, acmeAccount *domain.Ac
 	return acmeAccount, nil
 }
 
+func (r *AcmeAccountRepository) Delete(ctx context.Context, acmeAccount *domain.AcmeAccount) error {
+	record, err := app.GetApp().FindRecordById(domain.CollectionNameAcmeAccount, acmeAccount.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return app.GetApp().Delete(record)
+}
+
 func (r *AcmeAccountRepository) castRecordToModel(record *core.Record) (*domain.AcmeAccount, error) {
 	if record == nil {
 		return nil, fmt.Errorf("record is nil")
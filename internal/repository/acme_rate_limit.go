@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+type AcmeRateLimitRepository struct{}
+
+func NewAcmeRateLimitRepository() *AcmeRateLimitRepository {
+	return &AcmeRateLimitRepository{}
+}
+
+func (r *AcmeRateLimitRepository) GetByKey(ctx context.Context, key string) (*domain.AcmeRateLimit, error) {
+	record, err := app.GetApp().FindFirstRecordByFilter(
+		domain.CollectionNameAcmeRateLimit,
+		"key={:key}",
+		dbx.Params{"key": key},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return r.castRecordToModel(record)
+}
+
+func (r *AcmeRateLimitRepository) Save(ctx context.Context, acmeRateLimit *domain.AcmeRateLimit) (*domain.AcmeRateLimit, error) {
+	collection, err := app.GetApp().FindCollectionByNameOrId(domain.CollectionNameAcmeRateLimit)
+	if err != nil {
+		return acmeRateLimit, err
+	}
+
+	var record *core.Record
+	if acmeRateLimit.Id == "" {
+		record = core.NewRecord(collection)
+	} else {
+		record, err = app.GetApp().FindRecordById(collection, acmeRateLimit.Id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return acmeRateLimit, domain.ErrRecordNotFound
+			}
+			return acmeRateLimit, err
+		}
+	}
+
+	record.Set("key", acmeRateLimit.Key)
+	record.Set("blockedUntil", acmeRateLimit.BlockedUntil)
+	record.Set("failedValidations", acmeRateLimit.FailedValidations)
+	if err := app.GetApp().Save(record); err != nil {
+		return acmeRateLimit, err
+	}
+
+	acmeRateLimit.Id = record.Id
+	acmeRateLimit.CreatedAt = record.GetDateTime("created").Time()
+	acmeRateLimit.UpdatedAt = record.GetDateTime("updated").Time()
+	return acmeRateLimit, nil
+}
+
+func (r *AcmeRateLimitRepository) castRecordToModel(record *core.Record) (*domain.AcmeRateLimit, error) {
+	if record == nil {
+		return nil, fmt.Errorf("record is nil")
+	}
+
+	failedValidations := make(map[string][]time.Time)
+	if err := record.UnmarshalJSONField("failedValidations", &failedValidations); err != nil {
+		return nil, err
+	}
+
+	acmeRateLimit := &domain.AcmeRateLimit{
+		Meta: domain.Meta{
+			Id:        record.Id,
+			CreatedAt: record.GetDateTime("created").Time(),
+			UpdatedAt: record.GetDateTime("updated").Time(),
+		},
+		Key:               record.GetString("key"),
+		BlockedUntil:      record.GetDateTime("blockedUntil").Time(),
+		FailedValidations: failedValidations,
+	}
+	return acmeRateLimit, nil
+}
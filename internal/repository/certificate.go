@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 type CertificateRepository struct{}
@@ -42,6 +44,65 @@ func (r *CertificateRepository) ListExpireSoon(ctx context.Context) ([]*domain.C
 	return certificates, nil
 }
 
+// 列出指定 CA 在某一时间之后签发的证书，包括已删除的证书，用于统计 CA 的速率限制。
+func (r *CertificateRepository) ListByCAProviderCreatedAfter(ctx context.Context, caProvider string, since time.Time) ([]*domain.Certificate, error) {
+	records, err := app.GetApp().FindAllRecords(
+		domain.CollectionNameCertificate,
+		dbx.HashExp{"caProvider": caProvider},
+		dbx.NewExp("created>={:since}", dbx.Params{"since": since.UTC().Format(types.DefaultDateLayout)}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := make([]*domain.Certificate, 0)
+	for _, record := range records {
+		certificate, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+func (r *CertificateRepository) ListLatestByWorkflowId(ctx context.Context, workflowId string) ([]*domain.Certificate, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameCertificate,
+		"workflowId={:workflowId} && deleted=null",
+		"-created",
+		0, 0,
+		dbx.Params{"workflowId": workflowId},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个节点仅保留最近一次执行时申请的证书（可能有多种算法的证书）
+	certificates := make([]*domain.Certificate, 0)
+	latestRunIds := make(map[string]string)
+	for _, record := range records {
+		nodeId := record.GetString("workflowNodeId")
+		runId := record.GetString("workflowRunId")
+		if latestRunId, ok := latestRunIds[nodeId]; !ok {
+			latestRunIds[nodeId] = runId
+		} else if latestRunId != runId {
+			continue
+		}
+
+		certificate, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
 func (r *CertificateRepository) GetById(ctx context.Context, id string) (*domain.Certificate, error) {
 	record, err := app.GetApp().FindRecordById(domain.CollectionNameCertificate, id)
 	if err != nil {
@@ -77,6 +138,25 @@ func (r *CertificateRepository) GetByWorkflowNodeId(ctx context.Context, workflo
 	return r.castRecordToModel(records[0])
 }
 
+func (r *CertificateRepository) GetByWorkflowNodeIdAndKeyAlgorithm(ctx context.Context, workflowNodeId string, keyAlgorithm domain.CertificateKeyAlgorithmType) (*domain.Certificate, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameCertificate,
+		"workflowNodeId={:workflowNodeId} && keyAlgorithm={:keyAlgorithm} && deleted=null",
+		"-created",
+		1, 0,
+		dbx.Params{"workflowNodeId": workflowNodeId, "keyAlgorithm": string(keyAlgorithm)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, domain.ErrRecordNotFound
+	}
+
+	return r.castRecordToModel(records[0])
+}
+
 func (r *CertificateRepository) GetByWorkflowRunIdAndNodeId(ctx context.Context, workflowRunId string, workflowNodeId string) (*domain.Certificate, error) {
 	records, err := app.GetApp().FindRecordsByFilter(
 		domain.CollectionNameCertificate,
@@ -97,6 +177,31 @@ func (r *CertificateRepository) GetByWorkflowRunIdAndNodeId(ctx context.Context,
 	return r.castRecordToModel(records[0])
 }
 
+func (r *CertificateRepository) ListByWorkflowRunIdAndNodeId(ctx context.Context, workflowRunId string, workflowNodeId string) ([]*domain.Certificate, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameCertificate,
+		"workflowRunId={:workflowRunId} && workflowNodeId={:workflowNodeId} && deleted=null",
+		"-created",
+		0, 0,
+		dbx.Params{"workflowRunId": workflowRunId, "workflowNodeId": workflowNodeId},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := make([]*domain.Certificate, 0)
+	for _, record := range records {
+		certificate, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
 func (r *CertificateRepository) Save(ctx context.Context, certificate *domain.Certificate) (*domain.Certificate, error) {
 	collection, err := app.GetApp().FindCollectionByNameOrId(domain.CollectionNameCertificate)
 	if err != nil {
@@ -130,6 +235,16 @@ func (r *CertificateRepository) Save(ctx context.Context, certificate *domain.Ce
 	record.Set("acmeCertUrl", certificate.ACMECertUrl)
 	record.Set("acmeCertStableUrl", certificate.ACMECertStableUrl)
 	record.Set("acmeRenewed", certificate.ACMERenewed)
+	record.Set("acmeRenewalWindowStart", certificate.ACMERenewalWindowStart)
+	record.Set("acmeRenewalWindowEnd", certificate.ACMERenewalWindowEnd)
+	record.Set("acmeRenewalTime", certificate.ACMERenewalTime)
+	record.Set("revokedAt", certificate.RevokedAt)
+	record.Set("revocationReason", int32(certificate.RevocationReason))
+	record.Set("keyCreatedAt", certificate.KeyCreatedAt)
+	record.Set("caProvider", certificate.CAProvider)
+	record.Set("acmeRenewalInfoRetryAt", certificate.ACMERenewalInfoRetryAt)
+	record.Set("lastEventFired", string(certificate.LastEventFired))
+	record.Set("lastEventFiredAt", certificate.LastEventFiredAt)
 	record.Set("workflowId", certificate.WorkflowId)
 	record.Set("workflowRunId", certificate.WorkflowRunId)
 	record.Set("workflowNodeId", certificate.WorkflowNodeId)
@@ -178,24 +293,34 @@ func (r *CertificateRepository) castRecordToModel(record *core.Record) (*domain.
 			CreatedAt: record.GetDateTime("created").Time(),
 			UpdatedAt: record.GetDateTime("updated").Time(),
 		},
-		Source:            domain.CertificateSourceType(record.GetString("source")),
-		SubjectAltNames:   record.GetString("subjectAltNames"),
-		SerialNumber:      record.GetString("serialNumber"),
-		Certificate:       record.GetString("certificate"),
-		PrivateKey:        record.GetString("privateKey"),
-		IssuerOrg:         record.GetString("issuerOrg"),
-		IssuerCertificate: record.GetString("issuerCertificate"),
-		KeyAlgorithm:      domain.CertificateKeyAlgorithmType(record.GetString("keyAlgorithm")),
-		EffectAt:          record.GetDateTime("effectAt").Time(),
-		ExpireAt:          record.GetDateTime("expireAt").Time(),
-		ACMEAccountUrl:    record.GetString("acmeAccountUrl"),
-		ACMECertUrl:       record.GetString("acmeCertUrl"),
-		ACMECertStableUrl: record.GetString("acmeCertStableUrl"),
-		ACMERenewed:       record.GetBool("acmeRenewed"),
-		WorkflowId:        record.GetString("workflowId"),
-		WorkflowRunId:     record.GetString("workflowRunId"),
-		WorkflowNodeId:    record.GetString("workflowNodeId"),
-		WorkflowOutputId:  record.GetString("workflowOutputId"),
+		Source:                 domain.CertificateSourceType(record.GetString("source")),
+		SubjectAltNames:        record.GetString("subjectAltNames"),
+		SerialNumber:           record.GetString("serialNumber"),
+		Certificate:            record.GetString("certificate"),
+		PrivateKey:             record.GetString("privateKey"),
+		IssuerOrg:              record.GetString("issuerOrg"),
+		IssuerCertificate:      record.GetString("issuerCertificate"),
+		KeyAlgorithm:           domain.CertificateKeyAlgorithmType(record.GetString("keyAlgorithm")),
+		EffectAt:               record.GetDateTime("effectAt").Time(),
+		ExpireAt:               record.GetDateTime("expireAt").Time(),
+		ACMEAccountUrl:         record.GetString("acmeAccountUrl"),
+		ACMECertUrl:            record.GetString("acmeCertUrl"),
+		ACMECertStableUrl:      record.GetString("acmeCertStableUrl"),
+		ACMERenewed:            record.GetBool("acmeRenewed"),
+		ACMERenewalWindowStart: record.GetDateTime("acmeRenewalWindowStart").Time(),
+		ACMERenewalWindowEnd:   record.GetDateTime("acmeRenewalWindowEnd").Time(),
+		ACMERenewalTime:        record.GetDateTime("acmeRenewalTime").Time(),
+		RevokedAt:              record.GetDateTime("revokedAt").Time(),
+		RevocationReason:       domain.CertificateRevocationReasonType(record.GetInt("revocationReason")),
+		KeyCreatedAt:           record.GetDateTime("keyCreatedAt").Time(),
+		CAProvider:             record.GetString("caProvider"),
+		ACMERenewalInfoRetryAt: record.GetDateTime("acmeRenewalInfoRetryAt").Time(),
+		LastEventFired:         domain.WorkflowTriggerEventType(record.GetString("lastEventFired")),
+		LastEventFiredAt:       record.GetDateTime("lastEventFiredAt").Time(),
+		WorkflowId:             record.GetString("workflowId"),
+		WorkflowRunId:          record.GetString("workflowRunId"),
+		WorkflowNodeId:         record.GetString("workflowNodeId"),
+		WorkflowOutputId:       record.GetString("workflowOutputId"),
 	}
 	return certificate, nil
 }
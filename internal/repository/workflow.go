@@ -43,6 +43,31 @@ func (r *WorkflowRepository) ListEnabledAuto(ctx context.Context) ([]*domain.Wor
 	return workflows, nil
 }
 
+func (r *WorkflowRepository) ListEnabledByTrigger(ctx context.Context, trigger domain.WorkflowTriggerType) ([]*domain.Workflow, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameWorkflow,
+		"enabled={:enabled} && trigger={:trigger}",
+		"-created",
+		0, 0,
+		dbx.Params{"enabled": true, "trigger": string(trigger)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := make([]*domain.Workflow, 0)
+	for _, record := range records {
+		workflow, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		workflows = append(workflows, workflow)
+	}
+
+	return workflows, nil
+}
+
 func (r *WorkflowRepository) GetById(ctx context.Context, id string) (*domain.Workflow, error) {
 	record, err := app.GetApp().FindRecordById(domain.CollectionNameWorkflow, id)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/domain"
@@ -52,6 +53,18 @@ func (r *WorkflowOutputRepository) Save(ctx context.Context, workflowOutput *dom
 }
 
 func (r *WorkflowOutputRepository) SaveWithCertificate(ctx context.Context, workflowOutput *domain.WorkflowOutput, certificate *domain.Certificate) (*domain.WorkflowOutput, error) {
+	if certificate == nil {
+		panic("certificate is nil")
+	}
+
+	return r.SaveWithCertificates(ctx, workflowOutput, []*domain.Certificate{certificate})
+}
+
+func (r *WorkflowOutputRepository) SaveWithCertificates(ctx context.Context, workflowOutput *domain.WorkflowOutput, certificates []*domain.Certificate) (*domain.WorkflowOutput, error) {
+	if len(certificates) == 0 {
+		panic("certificates is empty")
+	}
+
 	record, err := r.saveRecord(workflowOutput)
 	if err != nil {
 		return workflowOutput, err
@@ -61,9 +74,11 @@ func (r *WorkflowOutputRepository) SaveWithCertificate(ctx context.Context, work
 		workflowOutput.UpdatedAt = record.GetDateTime("updated").Time()
 	}
 
-	if certificate == nil {
-		panic("certificate is nil")
-	} else {
+	for _, certificate := range certificates {
+		if certificate == nil {
+			panic("certificate is nil")
+		}
+
 		if certificate.WorkflowId != "" && certificate.WorkflowId != workflowOutput.WorkflowId {
 			return workflowOutput, fmt.Errorf("certificate #%s is not belong to workflow #%s", certificate.Id, workflowOutput.WorkflowId)
 		}
@@ -81,25 +96,44 @@ func (r *WorkflowOutputRepository) SaveWithCertificate(ctx context.Context, work
 		certificate.WorkflowRunId = workflowOutput.RunId
 		certificate.WorkflowNodeId = workflowOutput.NodeId
 		certificate.WorkflowOutputId = workflowOutput.Id
-		certificate, err := NewCertificateRepository().Save(ctx, certificate)
-		if err != nil {
+		if _, err := NewCertificateRepository().Save(ctx, certificate); err != nil {
 			return workflowOutput, err
 		}
+	}
 
-		// 写入证书 ID 到工作流输出结果中
-		for i, item := range workflowOutput.Outputs {
-			if item.Name == string(domain.WorkflowNodeIONameCertificate) {
-				workflowOutput.Outputs[i].Value = certificate.Id
-				break
-			}
+	// 写入证书 ID 到工作流输出结果中
+	// 申请多种算法的证书时，默认输出第一张证书的 ID，并按算法额外输出每张证书的 ID，形如 "certificate:${KeyAlgorithm}"
+	outputs := make([]domain.WorkflowNodeIO, 0, len(workflowOutput.Outputs)+len(certificates))
+	for _, item := range workflowOutput.Outputs {
+		if strings.HasPrefix(item.Name, domain.WorkflowNodeIONameCertificate+":") {
+			continue
 		}
-		record.Set("outputs", workflowOutput.Outputs)
-		if err := app.GetApp().Save(record); err != nil {
-			return workflowOutput, err
+
+		if item.Name == domain.WorkflowNodeIONameCertificate {
+			item.Value = certificates[0].Id
+			outputs = append(outputs, item)
+
+			if len(certificates) > 1 {
+				for _, certificate := range certificates {
+					variant := item
+					variant.Label = fmt.Sprintf("%s (%s)", item.Label, certificate.KeyAlgorithm)
+					variant.Name = fmt.Sprintf("%s:%s", item.Name, certificate.KeyAlgorithm)
+					variant.Value = certificate.Id
+					outputs = append(outputs, variant)
+				}
+			}
+			continue
 		}
+
+		outputs = append(outputs, item)
+	}
+	workflowOutput.Outputs = outputs
+	record.Set("outputs", workflowOutput.Outputs)
+	if err := app.GetApp().Save(record); err != nil {
+		return workflowOutput, err
 	}
 
-	return workflowOutput, err
+	return workflowOutput, nil
 }
 
 func (r *WorkflowOutputRepository) castRecordToModel(record *core.Record) (*domain.WorkflowOutput, error) {
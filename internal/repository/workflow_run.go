@@ -18,6 +18,31 @@ func NewWorkflowRunRepository() *WorkflowRunRepository {
 	return &WorkflowRunRepository{}
 }
 
+func (r *WorkflowRunRepository) ListByStatus(ctx context.Context, status domain.WorkflowRunStatusType) ([]*domain.WorkflowRun, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameWorkflowRun,
+		"status={:status}",
+		"startedAt,created",
+		0, 0,
+		dbx.Params{"status": string(status)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowRuns := make([]*domain.WorkflowRun, 0)
+	for _, record := range records {
+		workflowRun, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		workflowRuns = append(workflowRuns, workflowRun)
+	}
+
+	return workflowRuns, nil
+}
+
 func (r *WorkflowRunRepository) GetById(ctx context.Context, id string) (*domain.WorkflowRun, error) {
 	record, err := app.GetApp().FindRecordById(domain.CollectionNameWorkflowRun, id)
 	if err != nil {
@@ -57,6 +82,7 @@ func (r *WorkflowRunRepository) Save(ctx context.Context, workflowRun *domain.Wo
 		record.Set("endedAt", workflowRun.EndedAt)
 		record.Set("detail", workflowRun.Detail)
 		record.Set("error", workflowRun.Error)
+		record.Set("payload", workflowRun.Payload)
 		err = txApp.Save(record)
 		if err != nil {
 			return err
@@ -130,6 +156,13 @@ func (r *WorkflowRunRepository) castRecordToModel(record *core.Record) (*domain.
 		return nil, err
 	}
 
+	var payload map[string]any
+	if record.GetString("payload") != "" {
+		if err := record.UnmarshalJSONField("payload", &payload); err != nil {
+			return nil, err
+		}
+	}
+
 	workflowRun := &domain.WorkflowRun{
 		Meta: domain.Meta{
 			Id:        record.Id,
@@ -143,6 +176,7 @@ func (r *WorkflowRunRepository) castRecordToModel(record *core.Record) (*domain.
 		EndedAt:    record.GetDateTime("endedAt").Time(),
 		Detail:     detail,
 		Error:      record.GetString("error"),
+		Payload:    payload,
 	}
 	return workflowRun, nil
 }
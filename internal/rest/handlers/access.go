@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+type accessService interface {
+	TestDns01Provider(ctx context.Context, req *dtos.ApplicantTestDns01ProviderReq) (*dtos.ApplicantTestDns01ProviderResp, error)
+	GetAcmeDnsCNAMERecords(ctx context.Context, req *dtos.ApplicantGetAcmeDnsCNAMERecordsReq) (*dtos.ApplicantGetAcmeDnsCNAMERecordsResp, error)
+}
+
+type AccessHandler struct {
+	service accessService
+}
+
+func NewAccessHandler(router *router.RouterGroup[*core.RequestEvent], service accessService) {
+	handler := &AccessHandler{
+		service: service,
+	}
+
+	group := router.Group("/access")
+	group.POST("/test-dns01", handler.testDns01Provider)
+	group.POST("/{accessId}/acme-dns/cname-records", handler.getAcmeDnsCNAMERecords)
+}
+
+func (handler *AccessHandler) testDns01Provider(e *core.RequestEvent) error {
+	req := &dtos.ApplicantTestDns01ProviderReq{}
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if res, err := handler.service.TestDns01Provider(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
+
+func (handler *AccessHandler) getAcmeDnsCNAMERecords(e *core.RequestEvent) error {
+	req := &dtos.ApplicantGetAcmeDnsCNAMERecordsReq{}
+	req.AccessId = e.Request.PathValue("accessId")
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if res, err := handler.service.GetAcmeDnsCNAMERecords(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
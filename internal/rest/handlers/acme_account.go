@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+type acmeAccountService interface {
+	ListAcmeAccounts(ctx context.Context, req *dtos.ApplicantListAcmeAccountsReq) (*dtos.ApplicantListAcmeAccountsResp, error)
+	ImportAcmeAccount(ctx context.Context, req *dtos.ApplicantImportAcmeAccountReq) (*dtos.ApplicantAcmeAccount, error)
+	RolloverAcmeAccountKey(ctx context.Context, req *dtos.ApplicantRolloverAcmeAccountKeyReq) (*dtos.ApplicantAcmeAccount, error)
+	DeactivateAcmeAccount(ctx context.Context, req *dtos.ApplicantDeactivateAcmeAccountReq) error
+	UpdateAcmeAccountContact(ctx context.Context, req *dtos.ApplicantUpdateAcmeAccountContactReq) (*dtos.ApplicantAcmeAccount, error)
+}
+
+type AcmeAccountHandler struct {
+	service acmeAccountService
+}
+
+func NewAcmeAccountHandler(router *router.RouterGroup[*core.RequestEvent], service acmeAccountService) {
+	handler := &AcmeAccountHandler{
+		service: service,
+	}
+
+	group := router.Group("/acme-accounts")
+	group.GET("", handler.list)
+	group.POST("/import", handler.importAccount)
+	group.POST("/{accountId}/key-rollover", handler.rolloverKey)
+	group.POST("/{accountId}/deactivate", handler.deactivate)
+	group.POST("/{accountId}/contact", handler.updateContact)
+}
+
+func (handler *AcmeAccountHandler) list(e *core.RequestEvent) error {
+	req := &dtos.ApplicantListAcmeAccountsReq{}
+
+	if res, err := handler.service.ListAcmeAccounts(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
+
+func (handler *AcmeAccountHandler) importAccount(e *core.RequestEvent) error {
+	req := &dtos.ApplicantImportAcmeAccountReq{}
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if res, err := handler.service.ImportAcmeAccount(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
+
+func (handler *AcmeAccountHandler) rolloverKey(e *core.RequestEvent) error {
+	req := &dtos.ApplicantRolloverAcmeAccountKeyReq{}
+	req.AccountId = e.Request.PathValue("accountId")
+
+	if res, err := handler.service.RolloverAcmeAccountKey(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
+
+func (handler *AcmeAccountHandler) deactivate(e *core.RequestEvent) error {
+	req := &dtos.ApplicantDeactivateAcmeAccountReq{}
+	req.AccountId = e.Request.PathValue("accountId")
+
+	if err := handler.service.DeactivateAcmeAccount(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, nil)
+}
+
+func (handler *AcmeAccountHandler) updateContact(e *core.RequestEvent) error {
+	req := &dtos.ApplicantUpdateAcmeAccountContactReq{}
+	req.AccountId = e.Request.PathValue("accountId")
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if res, err := handler.service.UpdateAcmeAccountContact(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
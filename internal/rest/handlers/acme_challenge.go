@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+)
+
+type acmeChallengeService interface {
+	GetHttp01KeyAuthorization(ctx context.Context, req *dtos.ApplicantGetHttp01KeyAuthorizationReq) (*dtos.ApplicantGetHttp01KeyAuthorizationResp, error)
+}
+
+type ACMEChallengeHandler struct {
+	service acmeChallengeService
+}
+
+func NewACMEChallengeHandler(router *router.RouterGroup[*core.RequestEvent], service acmeChallengeService) {
+	handler := &ACMEChallengeHandler{
+		service: service,
+	}
+
+	group := router.Group("/.well-known/acme-challenge")
+	group.GET("/{token}", handler.getHttp01KeyAuthorization)
+}
+
+func (handler *ACMEChallengeHandler) getHttp01KeyAuthorization(e *core.RequestEvent) error {
+	req := &dtos.ApplicantGetHttp01KeyAuthorizationReq{}
+	req.Token = e.Request.PathValue("token")
+
+	// ACME 服务端要求以纯文本形式响应密钥授权内容，因此这里不使用统一的 JSON 响应格式
+	if res, err := handler.service.GetHttp01KeyAuthorization(e.Request.Context(), req); err != nil {
+		if domain.IsRecordNotFoundError(err) {
+			return e.String(http.StatusNotFound, "")
+		}
+		return e.String(http.StatusBadRequest, "")
+	} else {
+		return e.String(http.StatusOK, res.KeyAuthorization)
+	}
+}
@@ -14,6 +14,7 @@ type certificateService interface {
 	ArchiveFile(ctx context.Context, req *dtos.CertificateArchiveFileReq) (*dtos.CertificateArchiveFileResp, error)
 	ValidateCertificate(ctx context.Context, req *dtos.CertificateValidateCertificateReq) (*dtos.CertificateValidateCertificateResp, error)
 	ValidatePrivateKey(ctx context.Context, req *dtos.CertificateValidatePrivateKeyReq) (*dtos.CertificateValidatePrivateKeyResp, error)
+	RevokeCertificate(ctx context.Context, req *dtos.CertificateRevokeReq) error
 }
 
 type CertificateHandler struct {
@@ -27,6 +28,7 @@ func NewCertificateHandler(router *router.RouterGroup[*core.RequestEvent], servi
 
 	group := router.Group("/certificates")
 	group.POST("/{certificateId}/archive", handler.archiveFile)
+	group.POST("/{certificateId}/revoke", handler.revoke)
 	group.POST("/validate/certificate", handler.validateCertificate)
 	group.POST("/validate/private-key", handler.validatePrivateKey)
 }
@@ -45,6 +47,20 @@ func (handler *CertificateHandler) archiveFile(e *core.RequestEvent) error {
 	}
 }
 
+func (handler *CertificateHandler) revoke(e *core.RequestEvent) error {
+	req := &dtos.CertificateRevokeReq{}
+	req.CertificateId = e.Request.PathValue("certificateId")
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if err := handler.service.RevokeCertificate(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, nil)
+}
+
 func (handler *CertificateHandler) validateCertificate(e *core.RequestEvent) error {
 	req := &dtos.CertificateValidateCertificateReq{}
 	if err := e.BindBody(req); err != nil {
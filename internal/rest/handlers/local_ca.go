@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+type localCAService interface {
+	GenerateLocalCA(ctx context.Context, req *dtos.ApplicantGenerateLocalCAReq) (*dtos.ApplicantGetLocalCAChainResp, error)
+	ImportLocalCA(ctx context.Context, req *dtos.ApplicantImportLocalCAReq) (*dtos.ApplicantGetLocalCAChainResp, error)
+	GetLocalCAChain(ctx context.Context, req *dtos.ApplicantGetLocalCAChainReq) (*dtos.ApplicantGetLocalCAChainResp, error)
+}
+
+type LocalCAHandler struct {
+	service localCAService
+}
+
+func NewLocalCAHandler(router *router.RouterGroup[*core.RequestEvent], service localCAService) {
+	handler := &LocalCAHandler{
+		service: service,
+	}
+
+	group := router.Group("/local-ca")
+	group.POST("/{accessId}/generate", handler.generate)
+	group.POST("/{accessId}/import", handler.importCA)
+	group.GET("/{accessId}/chain", handler.getChain)
+}
+
+func (handler *LocalCAHandler) generate(e *core.RequestEvent) error {
+	req := &dtos.ApplicantGenerateLocalCAReq{}
+	req.AccessId = e.Request.PathValue("accessId")
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if res, err := handler.service.GenerateLocalCA(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
+
+func (handler *LocalCAHandler) importCA(e *core.RequestEvent) error {
+	req := &dtos.ApplicantImportLocalCAReq{}
+	req.AccessId = e.Request.PathValue("accessId")
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if res, err := handler.service.ImportLocalCA(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
+
+func (handler *LocalCAHandler) getChain(e *core.RequestEvent) error {
+	req := &dtos.ApplicantGetLocalCAChainReq{}
+	req.AccessId = e.Request.PathValue("accessId")
+
+	if res, err := handler.service.GetLocalCAChain(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+type sshHostKeyService interface {
+	FetchSSHHostKey(ctx context.Context, req *dtos.DeployerFetchSSHHostKeyReq) (*dtos.DeployerFetchSSHHostKeyResp, error)
+	ApproveSSHHostKey(ctx context.Context, req *dtos.DeployerApproveSSHHostKeyReq) error
+}
+
+type SSHHostKeyHandler struct {
+	service sshHostKeyService
+}
+
+func NewSSHHostKeyHandler(router *router.RouterGroup[*core.RequestEvent], service sshHostKeyService) {
+	handler := &SSHHostKeyHandler{
+		service: service,
+	}
+
+	group := router.Group("/access/{accessId}/ssh-host-key")
+	group.POST("/fetch", handler.fetch)
+	group.POST("/approve", handler.approve)
+}
+
+func (handler *SSHHostKeyHandler) fetch(e *core.RequestEvent) error {
+	req := &dtos.DeployerFetchSSHHostKeyReq{}
+	req.AccessId = e.Request.PathValue("accessId")
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if res, err := handler.service.FetchSSHHostKey(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
+
+func (handler *SSHHostKeyHandler) approve(e *core.RequestEvent) error {
+	req := &dtos.DeployerApproveSSHHostKeyReq{}
+	req.AccessId = e.Request.PathValue("accessId")
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	if err := handler.service.ApproveSSHHostKey(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, nil)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"io"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+// Webhook 请求体的最大字节数
+const webhookMaxBodySize = 1 << 20
+
+type workflowWebhookService interface {
+	TriggerWebhook(ctx context.Context, req *dtos.WorkflowTriggerWebhookReq) (*dtos.WorkflowTriggerWebhookResp, error)
+}
+
+type WorkflowWebhookHandler struct {
+	service workflowWebhookService
+}
+
+func NewWorkflowWebhookHandler(router *router.RouterGroup[*core.RequestEvent], service workflowWebhookService) {
+	handler := &WorkflowWebhookHandler{
+		service: service,
+	}
+
+	group := router.Group("/webhooks/workflows")
+	group.POST("/{workflowId}/{token}", handler.trigger)
+}
+
+func (handler *WorkflowWebhookHandler) trigger(e *core.RequestEvent) error {
+	body, err := io.ReadAll(io.LimitReader(e.Request.Body, webhookMaxBodySize+1))
+	if err != nil {
+		return resp.Err(e, err)
+	} else if len(body) > webhookMaxBodySize {
+		return resp.Err(e, domain.NewError(413, "request body too large"))
+	}
+
+	req := &dtos.WorkflowTriggerWebhookReq{}
+	req.WorkflowId = e.Request.PathValue("workflowId")
+	req.Token = e.Request.PathValue("token")
+	req.Signature = e.Request.Header.Get("X-Certimate-Signature")
+	req.Body = body
+
+	if res, err := handler.service.TriggerWebhook(e.Request.Context(), req); err != nil {
+		return resp.Err(e, err)
+	} else {
+		return resp.Ok(e, res)
+	}
+}
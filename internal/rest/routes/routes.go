@@ -7,7 +7,9 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/router"
 
+	"github.com/certimate-go/certimate/internal/applicant"
 	"github.com/certimate-go/certimate/internal/certificate"
+	"github.com/certimate-go/certimate/internal/deployer"
 	"github.com/certimate-go/certimate/internal/notify"
 	"github.com/certimate-go/certimate/internal/repository"
 	"github.com/certimate-go/certimate/internal/rest/handlers"
@@ -16,20 +18,26 @@ import (
 )
 
 var (
+	applicantSvc   *applicant.ApplicantService
 	certificateSvc *certificate.CertificateService
+	deployerSvc    *deployer.DeployerService
 	workflowSvc    *workflow.WorkflowService
 	statisticsSvc  *statistics.StatisticsService
 	notifySvc      *notify.NotifyService
 )
 
 func Register(router *router.Router[*core.RequestEvent]) {
+	accessRepo := repository.NewAccessRepository()
+	acmeAccountRepo := repository.NewAcmeAccountRepository()
 	workflowRepo := repository.NewWorkflowRepository()
 	workflowRunRepo := repository.NewWorkflowRunRepository()
 	certificateRepo := repository.NewCertificateRepository()
 	settingsRepo := repository.NewSettingsRepository()
 	statisticsRepo := repository.NewStatisticsRepository()
 
+	applicantSvc = applicant.NewApplicantService(accessRepo, acmeAccountRepo)
 	certificateSvc = certificate.NewCertificateService(certificateRepo, settingsRepo)
+	deployerSvc = deployer.NewDeployerService(accessRepo)
 	workflowSvc = workflow.NewWorkflowService(workflowRepo, workflowRunRepo, settingsRepo)
 	statisticsSvc = statistics.NewStatisticsService(statisticsRepo)
 	notifySvc = notify.NewNotifyService(settingsRepo)
@@ -40,6 +48,15 @@ func Register(router *router.Router[*core.RequestEvent]) {
 	handlers.NewWorkflowHandler(group, workflowSvc)
 	handlers.NewStatisticsHandler(group, statisticsSvc)
 	handlers.NewNotifyHandler(group, notifySvc)
+	handlers.NewLocalCAHandler(group, applicantSvc)
+	handlers.NewAcmeAccountHandler(group, applicantSvc)
+	handlers.NewAccessHandler(group, applicantSvc)
+	handlers.NewSSHHostKeyHandler(group, deployerSvc)
+
+	// 以下路由需由外部系统匿名访问，因此不能要求超级用户认证
+	// ACME HTTP-01 质询由 CA 访问；工作流 Webhook 通过 URL 中的密钥令牌鉴权
+	handlers.NewACMEChallengeHandler(router.RouterGroup, applicantSvc)
+	handlers.NewWorkflowWebhookHandler(router.RouterGroup, workflowSvc)
 }
 
 func Unregister() {
@@ -1,11 +1,36 @@
 package scheduler
 
-import "context"
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"github.com/certimate-go/certimate/internal/app"
+)
 
 type certificateService interface {
 	InitSchedule(ctx context.Context) error
 }
 
+type certificateEventChecker interface {
+	Start(ctx context.Context)
+	Wait()
+}
+
 func InitCertificateScheduler(service certificateService) error {
 	return service.InitSchedule(context.Background())
 }
+
+func InitCertificateEventChecker(checker certificateEventChecker) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	checker.Start(ctx)
+
+	// 服务终止时停止检查，并等待正在进行的检查退出
+	app.GetApp().OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		cancel()
+		checker.Wait()
+		return e.Next()
+	})
+
+	return nil
+}
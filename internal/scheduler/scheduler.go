@@ -15,6 +15,7 @@ func Register() {
 
 	workflowSvc := workflow.NewWorkflowService(workflowRepo, workflowRunRepo, settingsRepo)
 	certificateSvc := certificate.NewCertificateService(certificateRepo, settingsRepo)
+	certificateEventChecker := certificate.NewCertificateEventChecker(certificateRepo, workflowRepo, workflowSvc)
 
 	if err := InitWorkflowScheduler(workflowSvc); err != nil {
 		app.GetLogger().Error("failed to init workflow scheduler", "err", err)
@@ -23,4 +24,8 @@ func Register() {
 	if err := InitCertificateScheduler(certificateSvc); err != nil {
 		app.GetLogger().Error("failed to init certificate scheduler", "err", err)
 	}
+
+	if err := InitCertificateEventChecker(certificateEventChecker); err != nil {
+		app.GetLogger().Error("failed to init certificate event checker", "err", err)
+	}
 }
@@ -10,6 +10,7 @@ import (
 	"runtime/debug"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/certimate-go/certimate/internal/app"
@@ -17,7 +18,19 @@ import (
 	xslices "github.com/certimate-go/certimate/pkg/utils/slices"
 )
 
-var maxWorkers = 1
+const (
+	orphanedRunPolicyFail    = "fail"
+	orphanedRunPolicyRequeue = "requeue"
+)
+
+var (
+	maxWorkers        = 1
+	orphanedRunPolicy = orphanedRunPolicyFail
+	shutdownTimeout   = 60 * time.Second
+	defaultRunTimeout = time.Duration(0)
+)
+
+var errRunTimeout = errors.New("workflow run timed out")
 
 func init() {
 	envMaxWorkers := os.Getenv("CERTIMATE_WORKFLOW_MAX_WORKERS")
@@ -29,6 +42,26 @@ func init() {
 			maxWorkers = max(1, runtime.NumCPU())
 		}
 	}
+
+	// 服务启动时，对上次退出时仍处于执行中状态的 WorkflowRun 的处理策略：
+	//   - "fail": 标记为执行失败（默认）；
+	//   - "requeue": 重新排队执行。
+	envOrphanedRunPolicy := os.Getenv("CERTIMATE_WORKFLOW_ORPHANED_RUN_POLICY")
+	if envOrphanedRunPolicy == orphanedRunPolicyRequeue {
+		orphanedRunPolicy = orphanedRunPolicyRequeue
+	}
+
+	// 服务关闭时，等待正在执行的 WorkflowRun 完成的超时时间（单位：秒）
+	envShutdownTimeout := os.Getenv("CERTIMATE_WORKFLOW_SHUTDOWN_TIMEOUT")
+	if n, err := strconv.Atoi(envShutdownTimeout); err == nil && n >= 0 {
+		shutdownTimeout = time.Duration(n) * time.Second
+	}
+
+	// 单次 WorkflowRun 的默认最长执行时间（单位：秒），零值时不限制；可在工作流开始节点中单独配置
+	envRunTimeout := os.Getenv("CERTIMATE_WORKFLOW_RUN_TIMEOUT")
+	if n, err := strconv.Atoi(envRunTimeout); err == nil && n > 0 {
+		defaultRunTimeout = time.Duration(n) * time.Second
+	}
 }
 
 type workflowWorker struct {
@@ -40,6 +73,7 @@ type WorkflowWorkerData struct {
 	WorkflowId      string
 	WorkflowContent *domain.WorkflowNode
 	RunId           string
+	RunPayload      map[string]any
 }
 
 type WorkflowDispatcher struct {
@@ -57,6 +91,8 @@ type WorkflowDispatcher struct {
 
 	wg sync.WaitGroup
 
+	shuttingDown atomic.Bool
+
 	workflowRepo    workflowRepository
 	workflowRunRepo workflowRunRepository
 	workflowLogRepo workflowLogRepository
@@ -96,6 +132,61 @@ func newWorkflowDispatcher(workflowRepo workflowRepository, workflowRunRepo work
 	return dispatcher
 }
 
+func (d *WorkflowDispatcher) Recover(ctx context.Context) error {
+	// 上次退出时仍处于执行中状态的 WorkflowRun 已无对应的执行者，根据策略标记为失败或重新排队
+	orphanedRuns, err := d.workflowRunRepo.ListByStatus(ctx, domain.WorkflowRunStatusTypeRunning)
+	if err != nil {
+		return fmt.Errorf("failed to list running workflow runs: %w", err)
+	}
+
+	for _, run := range orphanedRuns {
+		if d.hasRun(run.Id) {
+			continue
+		}
+
+		switch orphanedRunPolicy {
+		case orphanedRunPolicyRequeue:
+			run.Status = domain.WorkflowRunStatusTypePending
+
+		default:
+			run.Status = domain.WorkflowRunStatusTypeFailed
+			run.EndedAt = time.Now()
+			run.Error = "workflow run was interrupted by an unexpected shutdown"
+		}
+
+		if _, err := d.workflowRunRepo.Save(ctx, run); err != nil {
+			return fmt.Errorf("failed to save orphaned workflow run #%s: %w", run.Id, err)
+		}
+
+		app.GetLogger().Warn(fmt.Sprintf("orphaned workflow run #%s has been marked as %s", run.Id, run.Status))
+	}
+
+	// 重建排队队列
+	pendingRuns, err := d.workflowRunRepo.ListByStatus(ctx, domain.WorkflowRunStatusTypePending)
+	if err != nil {
+		return fmt.Errorf("failed to list pending workflow runs: %w", err)
+	}
+
+	for _, run := range pendingRuns {
+		if d.hasRun(run.Id) {
+			continue
+		}
+
+		d.Dispatch(&WorkflowWorkerData{
+			WorkflowId:      run.WorkflowId,
+			WorkflowContent: run.Detail,
+			RunId:           run.Id,
+			RunPayload:      run.Payload,
+		})
+	}
+
+	if len(pendingRuns) > 0 {
+		app.GetLogger().Info(fmt.Sprintf("recovered %d pending workflow runs", len(pendingRuns)))
+	}
+
+	return nil
+}
+
 func (d *WorkflowDispatcher) Dispatch(data *WorkflowWorkerData) {
 	if data == nil {
 		panic("worker data is nil")
@@ -142,21 +233,59 @@ func (d *WorkflowDispatcher) Cancel(runId string) {
 	}
 }
 
-func (d *WorkflowDispatcher) Shutdown() {
-	// 清空排队中的 WorkflowRun
+func (d *WorkflowDispatcher) Shutdown(ctx context.Context) {
+	// 停止取出排队中的 WorkflowRun
+	// 这些 WorkflowRun 仍以 Pending 状态持久化，将在下次启动时恢复
+	d.shuttingDown.Store(true)
 	d.queueMutex.Lock()
 	d.queue = make([]*WorkflowWorkerData, 0)
 	d.queueMutex.Unlock()
 
 	// 等待所有正在执行的 WorkflowRun 完成
+	chDone := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(chDone)
+	}()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+
+	select {
+	case <-chDone:
+		return
+	case <-timeoutCtx.Done():
+	}
+
+	// 超时后取消仍在执行的 WorkflowRun
 	d.workerMutex.Lock()
 	for _, worker := range d.workers {
+		app.GetLogger().Warn(fmt.Sprintf("workflow run #%s is still running after shutdown timeout, canceling", worker.Data.RunId))
 		worker.Cancel()
 		delete(d.workers, worker.Data.WorkflowId)
 		delete(d.workerIdMap, worker.Data.RunId)
 	}
 	d.workerMutex.Unlock()
-	d.wg.Wait()
+	<-chDone
+}
+
+func (d *WorkflowDispatcher) hasRun(runId string) bool {
+	d.workerMutex.Lock()
+	_, running := d.workerIdMap[runId]
+	d.workerMutex.Unlock()
+	if running {
+		return true
+	}
+
+	d.queueMutex.Lock()
+	defer d.queueMutex.Unlock()
+	for _, data := range d.queue {
+		if data.RunId == runId {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (d *WorkflowDispatcher) enqueueWorker(data *WorkflowWorkerData) {
@@ -167,6 +296,10 @@ func (d *WorkflowDispatcher) enqueueWorker(data *WorkflowWorkerData) {
 
 func (d *WorkflowDispatcher) dequeueWorker() {
 	for {
+		if d.shuttingDown.Load() {
+			return
+		}
+
 		select {
 		case d.semaphore <- struct{}{}:
 		default:
@@ -255,6 +388,11 @@ func (d *WorkflowDispatcher) work(ctx context.Context, data *WorkflowWorkerData)
 	} else if run.Status != domain.WorkflowRunStatusTypePending {
 		return
 	} else if ctx.Err() != nil {
+		if d.shuttingDown.Load() {
+			// 因服务关闭而中断，保持 Pending 状态，待下次启动时恢复
+			return
+		}
+
 		run.Status = domain.WorkflowRunStatusTypeCanceled
 		d.workflowRunRepo.Save(ctx, run)
 		return
@@ -270,9 +408,28 @@ func (d *WorkflowDispatcher) work(ctx context.Context, data *WorkflowWorkerData)
 	}
 
 	// 执行工作流
+	runCtx := ctx
+	runTimeout := getRunTimeout(data.WorkflowContent)
+	if runTimeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, runTimeout, errRunTimeout)
+		defer cancel()
+	}
+
 	invoker := newWorkflowInvokerWithData(d.workflowLogRepo, data)
-	if runErr := invoker.Invoke(ctx); runErr != nil {
-		if errors.Is(runErr, context.Canceled) {
+	if runErr := invoker.Invoke(runCtx); runErr != nil {
+		if ctx.Err() == nil && errors.Is(context.Cause(runCtx), errRunTimeout) {
+			// 超时与取消区分开来，超时视为执行失败
+			run.Status = domain.WorkflowRunStatusTypeFailed
+			run.EndedAt = time.Now()
+			run.Error = fmt.Sprintf("workflow run timed out after %s", runTimeout)
+		} else if errors.Is(runErr, context.Canceled) {
+			if d.shuttingDown.Load() {
+				// 因服务关闭而中断，保持 Running 状态，待下次启动时按孤儿策略处理
+				app.GetLogger().Warn(fmt.Sprintf("workflow run #%s was interrupted by shutdown", data.RunId))
+				return
+			}
+
 			run.Status = domain.WorkflowRunStatusTypeCanceled
 		} else {
 			run.Status = domain.WorkflowRunStatusTypeFailed
@@ -303,3 +460,13 @@ func (d *WorkflowDispatcher) work(ctx context.Context, data *WorkflowWorkerData)
 		}
 	}
 }
+
+func getRunTimeout(workflowContent *domain.WorkflowNode) time.Duration {
+	if workflowContent != nil && workflowContent.Type == domain.WorkflowNodeTypeStart {
+		if timeout := workflowContent.GetConfigForStart().RunTimeout; timeout > 0 {
+			return time.Duration(timeout) * time.Second
+		}
+	}
+
+	return defaultRunTimeout
+}
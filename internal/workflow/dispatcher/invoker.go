@@ -3,18 +3,39 @@ package dispatcher
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/certimate-go/certimate/internal/domain"
 	nodes "github.com/certimate-go/certimate/internal/workflow/node-processor"
 	"github.com/certimate-go/certimate/pkg/logging"
 )
 
+var (
+	errNodeTimeout      = errors.New("node execution timed out")
+	errNodeStillRunning = errors.New("node is still running in the background")
+)
+
+// 节点超时后等待其响应取消并退出的宽限期。
+// 超过宽限期仍未退出的节点将被放弃，且不再重试，以免与仍在执行的上一次尝试并发（如重复申请证书或写入相同的文件）。
+var nodeExitGracePeriod = 30 * time.Second
+
+// 获取节点处理器，便于测试时替换。
+var getNodeProcessor = nodes.GetProcessor
+
 type workflowInvoker struct {
 	workflowId      string
 	workflowContent *domain.WorkflowNode
 	runId           string
+	runPayload      map[string]any
 	logs            []domain.WorkflowLog
+	logsMtx         sync.Mutex
+	semaphore       chan struct{} // 整个工作流共享的并发限制，各层级嵌套的并行分支均受其约束
 
 	workflowLogRepo workflowLogRepository
 }
@@ -24,11 +45,18 @@ func newWorkflowInvokerWithData(workflowLogRepo workflowLogRepository, data *Wor
 		panic("worker data is nil")
 	}
 
+	maxParallelism := 1
+	if data.WorkflowContent != nil && data.WorkflowContent.Type == domain.WorkflowNodeTypeStart {
+		maxParallelism = max(1, int(data.WorkflowContent.GetConfigForStart().MaxParallelism))
+	}
+
 	return &workflowInvoker{
 		workflowId:      data.WorkflowId,
 		workflowContent: data.WorkflowContent,
 		runId:           data.RunId,
+		runPayload:      data.RunPayload,
 		logs:            make([]domain.WorkflowLog, 0),
+		semaphore:       make(chan struct{}, maxParallelism),
 
 		workflowLogRepo: workflowLogRepo,
 	}
@@ -37,11 +65,16 @@ func newWorkflowInvokerWithData(workflowLogRepo workflowLogRepository, data *Wor
 func (w *workflowInvoker) Invoke(ctx context.Context) error {
 	ctx = context.WithValue(ctx, "workflow_id", w.workflowId)
 	ctx = context.WithValue(ctx, "workflow_run_id", w.runId)
+	ctx = context.WithValue(ctx, "workflow_run_payload", w.runPayload)
+	ctx = nodes.InitNodeOutputs(ctx)
 	return w.processNode(ctx, w.workflowContent)
 }
 
 func (w *workflowInvoker) GetLogs() domain.WorkflowLogs {
-	return w.logs
+	w.logsMtx.Lock()
+	defer w.logsMtx.Unlock()
+
+	return slices.Clone(w.logs)
 }
 
 func (w *workflowInvoker) processNode(ctx context.Context, node *domain.WorkflowNode) error {
@@ -53,64 +86,23 @@ func (w *workflowInvoker) processNode(ctx context.Context, node *domain.Workflow
 		default:
 		}
 
-		if current.Type == domain.WorkflowNodeTypeBranch || current.Type == domain.WorkflowNodeTypeExecuteResultBranch {
-			for _, branch := range current.Branches {
-				if err := w.processNode(ctx, &branch); err != nil {
-					// 并行分支的某一分支发生错误时，忽略此错误，继续执行其他分支
-					if !(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
-						continue
-					}
-					return err
-				}
+		if current.Type == domain.WorkflowNodeTypeBranch {
+			if err := w.processBranches(ctx, current.Branches, true); err != nil {
+				return err
+			}
+		} else if current.Type == domain.WorkflowNodeTypeExecuteResultBranch {
+			if err := w.processBranches(ctx, current.Branches, false); err != nil {
+				return err
 			}
 		}
 
-		var processor nodes.NodeProcessor
 		var procErr error
-		for {
-			if current.Type != domain.WorkflowNodeTypeBranch && current.Type != domain.WorkflowNodeTypeExecuteResultBranch {
-				processor, procErr = nodes.GetProcessor(current)
-				if procErr != nil {
-					panic(procErr)
-				}
-
-				processor.SetLogger(slog.New(logging.NewHookHandler(&logging.HookHandlerOptions{
-					Level: slog.LevelDebug,
-					WriteFunc: func(ctx context.Context, record *logging.Record) error {
-						log := domain.WorkflowLog{}
-						log.WorkflowId = w.workflowId
-						log.RunId = w.runId
-						log.NodeId = current.Id
-						log.NodeName = current.Name
-						log.Timestamp = record.Time.UnixMilli()
-						log.Level = record.Level.String()
-						log.Message = record.Message
-						log.Data = record.Data
-						log.CreatedAt = record.Time
-						if _, err := w.workflowLogRepo.Save(ctx, &log); err != nil {
-							return err
-						}
-
-						w.logs = append(w.logs, log)
-						return nil
-					},
-				})))
-
-				procErr = processor.Process(ctx)
-				if procErr != nil {
-					if current.Type != domain.WorkflowNodeTypeCondition {
-						processor.GetLogger().Error(procErr.Error())
-					}
-					break
-				}
-
-				nodeOutputs := processor.GetOutputs()
-				if len(nodeOutputs) > 0 {
-					ctx = nodes.AddNodeOutput(ctx, current.Id, nodeOutputs)
-				}
+		if current.Type != domain.WorkflowNodeTypeBranch && current.Type != domain.WorkflowNodeTypeExecuteResultBranch {
+			var nodeOutputs map[string]any
+			nodeOutputs, procErr = w.processNodeWithRetry(ctx, current)
+			if procErr == nil && len(nodeOutputs) > 0 {
+				ctx = nodes.AddNodeOutput(ctx, current.Id, nodeOutputs)
 			}
-
-			break
 		}
 
 		// TODO: 优化可读性
@@ -132,6 +124,221 @@ func (w *workflowInvoker) processNode(ctx context.Context, node *domain.Workflow
 	return nil
 }
 
+func (w *workflowInvoker) processBranches(ctx context.Context, branches []domain.WorkflowNode, parallel bool) error {
+	if !parallel {
+		for i := range branches {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			w.processNode(ctx, &branches[i])
+		}
+
+		return ctx.Err()
+	}
+
+	wg := sync.WaitGroup{}
+
+	var panicValue any
+	var panicOnce sync.Once
+
+	// 分支本身不占用并发名额，仅在执行节点时占用，以免嵌套的并行分支因外层分支占满名额而死锁
+	for i := range branches {
+		wg.Add(1)
+		go func(branch *domain.WorkflowNode) {
+			defer func() {
+				// 将分支中的 panic 传递回调用方，由调度器统一处理
+				if r := recover(); r != nil {
+					panicOnce.Do(func() { panicValue = r })
+				}
+
+				wg.Done()
+			}()
+
+			// 并行分支的某一分支发生错误时，忽略此错误，继续执行其他分支
+			// 各分支共享同一个节点输出容器，其输出会自动合并回工作流上下文
+			w.processNode(ctx, branch)
+		}(&branches[i])
+	}
+
+	wg.Wait()
+
+	if panicValue != nil {
+		panic(panicValue)
+	}
+
+	// 除非是整个工作流被取消或超时
+	return ctx.Err()
+}
+
+func (w *workflowInvoker) processNodeWithRetry(ctx context.Context, node *domain.WorkflowNode) (map[string]any, error) {
+	policy := node.GetRetryPolicy()
+	if node.Type == domain.WorkflowNodeTypeCondition {
+		// 条件节点的错误表示条件不成立，无需重试
+		policy.MaxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		processor, err := getNodeProcessor(node)
+		if err != nil {
+			panic(err)
+		}
+
+		detached := &atomic.Bool{}
+		processor.SetLogger(w.newNodeLogger(node, detached))
+		if attempt > 1 {
+			processor.GetLogger().Info(fmt.Sprintf("retrying, attempt %d/%d ...", attempt, policy.MaxAttempts))
+		}
+
+		procErr := w.processNodeWithLimit(ctx, node, processor, detached)
+		if procErr == nil {
+			nodeOutputs := processor.GetOutputs()
+			nodeOutputs[nodes.OutputKeyForNodeAttempts] = strconv.Itoa(attempt)
+			return nodeOutputs, nil
+		}
+
+		// 仅最后一次失败记录为错误日志，以免重试成功后工作流仍被判定为执行失败
+		retryable := attempt < int(policy.MaxAttempts) && ctx.Err() == nil && isRetryableError(procErr, policy.RetryableErrors)
+		if !retryable {
+			if node.Type != domain.WorkflowNodeTypeCondition {
+				processor.GetLogger().Error(procErr.Error())
+			}
+			return nil, procErr
+		}
+
+		delay := getRetryDelay(policy, attempt)
+		processor.GetLogger().Warn(fmt.Sprintf("attempt %d/%d failed, will retry in %s: %s", attempt, policy.MaxAttempts, delay, procErr.Error()))
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (w *workflowInvoker) processNodeWithLimit(ctx context.Context, node *domain.WorkflowNode, processor nodes.NodeProcessor, detached *atomic.Bool) error {
+	select {
+	case w.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	stillRunning, err := w.processNodeWithTimeout(ctx, node, processor)
+	if stillRunning != nil {
+		// 被放弃的节点不再写入日志，以免在工作流执行结束后仍产生日志；
+		// 同时继续占用并发名额，直至其真正退出
+		detached.Store(true)
+		go func() {
+			<-stillRunning
+			<-w.semaphore
+		}()
+	} else {
+		<-w.semaphore
+	}
+
+	return err
+}
+
+// 在超时限制内执行节点。
+// 如果节点超时或工作流被取消后仍未退出，返回的通道将在其最终退出时关闭；否则返回 nil。
+func (w *workflowInvoker) processNodeWithTimeout(ctx context.Context, node *domain.WorkflowNode, processor nodes.NodeProcessor) (<-chan struct{}, error) {
+	timeout := node.GetTimeout()
+	if timeout <= 0 {
+		return nil, processor.Process(ctx)
+	}
+
+	nodeCtx, cancel := context.WithTimeoutCause(ctx, timeout, errNodeTimeout)
+	defer cancel()
+
+	// 部分提供商（如 lego 申请证书、SSH 命令等）可能不响应上下文的取消，
+	// 因此在独立的 goroutine 中执行节点，超时或取消后不会无限期阻塞工作流
+	type processResult struct {
+		err      error
+		panicked bool
+		panicVal any
+	}
+	resultCh := make(chan processResult, 1)
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer func() {
+			if r := recover(); r != nil {
+				resultCh <- processResult{panicked: true, panicVal: r}
+			}
+		}()
+
+		resultCh <- processResult{err: processor.Process(nodeCtx)}
+	}()
+
+	var res processResult
+	select {
+	case res = <-resultCh:
+
+	case <-nodeCtx.Done():
+		if ctx.Err() != nil {
+			select {
+			case res = <-resultCh:
+			default:
+				return exited, ctx.Err()
+			}
+			break
+		}
+
+		// 等待节点在宽限期内响应取消，只有其确实退出后才允许重试
+		grace := time.NewTimer(nodeExitGracePeriod)
+		defer grace.Stop()
+
+		select {
+		case res = <-resultCh:
+		case <-grace.C:
+			return exited, fmt.Errorf("node execution timed out after %s: %w", timeout, errNodeStillRunning)
+		}
+	}
+
+	if res.panicked {
+		panic(res.panicVal)
+	}
+
+	err := res.err
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(nodeCtx), errNodeTimeout) {
+		// 区分节点超时与工作流被取消
+		return nil, fmt.Errorf("node execution timed out after %s: %w: %w", timeout, context.DeadlineExceeded, err)
+	}
+
+	return nil, err
+}
+
+func (w *workflowInvoker) newNodeLogger(node *domain.WorkflowNode, detached *atomic.Bool) *slog.Logger {
+	return slog.New(logging.NewHookHandler(&logging.HookHandlerOptions{
+		Level: slog.LevelDebug,
+		WriteFunc: func(ctx context.Context, record *logging.Record) error {
+			if detached.Load() {
+				return nil
+			}
+
+			log := domain.WorkflowLog{}
+			log.WorkflowId = w.workflowId
+			log.RunId = w.runId
+			log.NodeId = node.Id
+			log.NodeName = node.Name
+			log.Timestamp = record.Time.UnixMilli()
+			log.Level = record.Level.String()
+			log.Message = record.Message
+			log.Data = record.Data
+			log.CreatedAt = record.Time
+			if _, err := w.workflowLogRepo.Save(ctx, &log); err != nil {
+				return err
+			}
+
+			w.logsMtx.Lock()
+			w.logs = append(w.logs, log)
+			w.logsMtx.Unlock()
+			return nil
+		},
+	}))
+}
+
 func (w *workflowInvoker) getBranchByType(branches []domain.WorkflowNode, nodeType domain.WorkflowNodeType) *domain.WorkflowNode {
 	for _, branch := range branches {
 		if branch.Type == nodeType {
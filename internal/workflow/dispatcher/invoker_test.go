@@ -0,0 +1,161 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	nodes "github.com/certimate-go/certimate/internal/workflow/node-processor"
+)
+
+// 不响应上下文取消的节点处理器。
+type ignoringCtxProcessor struct {
+	logger  *slog.Logger
+	process func() error
+}
+
+func (p *ignoringCtxProcessor) GetLogger() *slog.Logger       { return p.logger }
+func (p *ignoringCtxProcessor) SetLogger(l *slog.Logger)      { p.logger = l }
+func (p *ignoringCtxProcessor) Process(context.Context) error { return p.process() }
+func (p *ignoringCtxProcessor) GetOutputs() map[string]any    { return map[string]any{} }
+
+type memoryWorkflowLogRepository struct {
+	mtx  sync.Mutex
+	logs []domain.WorkflowLog
+}
+
+func (r *memoryWorkflowLogRepository) Save(ctx context.Context, log *domain.WorkflowLog) (*domain.WorkflowLog, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.logs = append(r.logs, *log)
+	return log, nil
+}
+
+func (r *memoryWorkflowLogRepository) messages() []string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	messages := make([]string, 0, len(r.logs))
+	for _, log := range r.logs {
+		messages = append(messages, log.Message)
+	}
+	return messages
+}
+
+func setTestNodeProcessor(t *testing.T, newProcessor func() nodes.NodeProcessor, gracePeriod time.Duration) {
+	origGetNodeProcessor, origGracePeriod := getNodeProcessor, nodeExitGracePeriod
+	t.Cleanup(func() {
+		getNodeProcessor, nodeExitGracePeriod = origGetNodeProcessor, origGracePeriod
+	})
+
+	getNodeProcessor = func(*domain.WorkflowNode) (nodes.NodeProcessor, error) { return newProcessor(), nil }
+	nodeExitGracePeriod = gracePeriod
+}
+
+func newTestInvoker(logRepo workflowLogRepository) *workflowInvoker {
+	return newWorkflowInvokerWithData(logRepo, &WorkflowWorkerData{WorkflowId: "test", RunId: "test"})
+}
+
+func newTestNode(maxAttempts int32) *domain.WorkflowNode {
+	return &domain.WorkflowNode{
+		Id:   "node",
+		Type: domain.WorkflowNodeTypeDeploy,
+		Config: map[string]any{
+			"timeout": 1,
+			"retryPolicy": map[string]any{
+				"maxAttempts":  maxAttempts,
+				"initialDelay": 1,
+			},
+		},
+	}
+}
+
+func TestProcessNodeWithRetry_TimedOutAttemptsNeverOverlap(t *testing.T) {
+	var running, maxRunning, attempts atomic.Int32
+	setTestNodeProcessor(t, func() nodes.NodeProcessor {
+		return &ignoringCtxProcessor{process: func() error {
+			attempts.Add(1)
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			// 超过节点超时时间，但在宽限期内退出
+			time.Sleep(1500 * time.Millisecond)
+			return errors.New("stuck")
+		}}
+	}, 2*time.Second)
+
+	invoker := newTestInvoker(&memoryWorkflowLogRepository{})
+	_, err := invoker.processNodeWithRetry(context.Background(), newTestNode(2))
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if errors.Is(err, errNodeStillRunning) {
+		t.Fatalf("error = %v, the attempt should have exited within the grace period", err)
+	}
+
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+	if got := maxRunning.Load(); got != 1 {
+		t.Errorf("max concurrent attempts = %d, want 1", got)
+	}
+}
+
+func TestProcessNodeWithRetry_StillRunningIsNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	exited := make(chan struct{})
+	setTestNodeProcessor(t, func() nodes.NodeProcessor {
+		p := &ignoringCtxProcessor{}
+		p.process = func() error {
+			defer close(exited)
+			attempts.Add(1)
+			<-release
+			p.logger.Info("late log")
+			return nil
+		}
+		return p
+	}, 100*time.Millisecond)
+
+	logRepo := &memoryWorkflowLogRepository{}
+	invoker := newTestInvoker(logRepo)
+	_, err := invoker.processNodeWithRetry(context.Background(), newTestNode(3))
+	if !errors.Is(err, errNodeStillRunning) {
+		t.Fatalf("error = %v, want errNodeStillRunning", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+
+	// 被放弃的节点退出前，仍占用并发名额
+	if got := len(invoker.semaphore); got != 1 {
+		t.Errorf("semaphore tokens in use = %d, want 1", got)
+	}
+
+	close(release)
+	<-exited
+	for deadline := time.Now().Add(time.Second); len(invoker.semaphore) != 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("semaphore token is not released after the node exits")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, message := range logRepo.messages() {
+		if message == "late log" {
+			t.Errorf("logs of an abandoned node should be dropped")
+		}
+	}
+}
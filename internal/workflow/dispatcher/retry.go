@@ -0,0 +1,85 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+var (
+	reRateLimitError = regexp.MustCompile(`(?i)\b429\b|too many requests|rate[ _-]?limit|throttl`)
+	reServerError    = regexp.MustCompile(`(?i)\b5\d{2}\b|internal server error|bad gateway|service unavailable|gateway timeout`)
+	reTimeoutError   = regexp.MustCompile(`(?i)timeout|timed out`)
+	reNetworkError   = regexp.MustCompile(`(?i)connection refused|connection reset|broken pipe|no such host|network is unreachable|unexpected eof`)
+)
+
+// 判断错误是否属于可重试的错误类别。
+// 由于各云服务商 SDK 返回的错误类型各不相同，这里除了判断标准错误类型外，还会匹配错误信息。
+func isRetryableError(err error, retryableErrors []domain.WorkflowNodeRetryableErrorType) bool {
+	if err == nil {
+		return false
+	}
+
+	// 被取消的执行不应重试
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// 超时后仍在后台执行的节点不应重试，以免与其并发执行
+	if errors.Is(err, errNodeStillRunning) {
+		return false
+	}
+
+	// 未指定错误类别时，任意错误均可重试
+	if len(retryableErrors) == 0 {
+		return true
+	}
+
+	for _, errType := range retryableErrors {
+		if matchErrorType(err, errType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchErrorType(err error, errType domain.WorkflowNodeRetryableErrorType) bool {
+	errMsg := err.Error()
+
+	switch errType {
+	case domain.WorkflowNodeRetryableErrorTypeNetwork:
+		var netErr net.Error
+		if errors.As(err, &netErr) && !netErr.Timeout() {
+			return true
+		}
+		return reNetworkError.MatchString(errMsg)
+
+	case domain.WorkflowNodeRetryableErrorTypeTimeout:
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return true
+		}
+		return reTimeoutError.MatchString(errMsg)
+
+	case domain.WorkflowNodeRetryableErrorTypeRateLimit:
+		return reRateLimitError.MatchString(errMsg)
+
+	case domain.WorkflowNodeRetryableErrorTypeServer:
+		return reServerError.MatchString(errMsg)
+	}
+
+	return false
+}
+
+// 计算第 n 次尝试失败后、下一次重试前的等待时间。
+func getRetryDelay(policy domain.WorkflowNodeRetryPolicy, attempt int) time.Duration {
+	delay := float64(policy.InitialDelay) * math.Pow(policy.BackoffFactor, float64(attempt-1))
+	delay = math.Min(delay, float64(policy.MaxDelay))
+	return time.Duration(delay * float64(time.Second))
+}
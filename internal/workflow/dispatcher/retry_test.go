@@ -0,0 +1,185 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	var (
+		typeNetwork   = domain.WorkflowNodeRetryableErrorTypeNetwork
+		typeTimeout   = domain.WorkflowNodeRetryableErrorTypeTimeout
+		typeRateLimit = domain.WorkflowNodeRetryableErrorTypeRateLimit
+		typeServer    = domain.WorkflowNodeRetryableErrorTypeServer
+	)
+
+	tests := []struct {
+		name            string
+		err             error
+		retryableErrors []domain.WorkflowNodeRetryableErrorType
+		want            bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "canceled",
+			err:  fmt.Errorf("failed to deploy: %w", context.Canceled),
+			want: false,
+		},
+		{
+			name:            "canceled with error types",
+			err:             context.Canceled,
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeNetwork, typeTimeout},
+			want:            false,
+		},
+		{
+			name: "any error without error types",
+			err:  errors.New("invalid access key"),
+			want: true,
+		},
+		{
+			name:            "network error type",
+			err:             &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", errors.New("connection refused"))},
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeNetwork},
+			want:            true,
+		},
+		{
+			name:            "network error message",
+			err:             errors.New("sdkerr: failed to send request: read tcp: connection reset by peer"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeNetwork},
+			want:            true,
+		},
+		{
+			name:            "deadline exceeded",
+			err:             fmt.Errorf("failed to execute: %w", context.DeadlineExceeded),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeTimeout},
+			want:            true,
+		},
+		{
+			name:            "deadline exceeded is not a network error",
+			err:             context.DeadlineExceeded,
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeNetwork},
+			want:            false,
+		},
+		{
+			name:            "timeout error message",
+			err:             errors.New("request timed out"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeTimeout},
+			want:            true,
+		},
+		{
+			name:            "rate limit status code",
+			err:             errors.New("sdkerr: unexpected status code: 429"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeRateLimit},
+			want:            true,
+		},
+		{
+			name:            "rate limit message",
+			err:             errors.New("RequestLimitExceeded: Too Many Requests"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeRateLimit},
+			want:            true,
+		},
+		{
+			name:            "throttled message",
+			err:             errors.New("Throttling.User: request was denied due to user flow control"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeRateLimit},
+			want:            true,
+		},
+		{
+			name:            "server error status code",
+			err:             errors.New("sdkerr: unexpected status code: 502"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeServer},
+			want:            true,
+		},
+		{
+			name:            "server error message",
+			err:             errors.New("Service Unavailable"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeServer},
+			want:            true,
+		},
+		{
+			name:            "client error is permanent",
+			err:             errors.New("sdkerr: unexpected status code: 403, InvalidAccessKeyId"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeNetwork, typeTimeout, typeRateLimit, typeServer},
+			want:            false,
+		},
+		{
+			name:            "5xx-like number inside a word is permanent",
+			err:             errors.New("certificate id cert500x not found"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeServer},
+			want:            false,
+		},
+		{
+			name:            "rate limit is not a server error",
+			err:             errors.New("unexpected status code: 429"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{typeServer},
+			want:            false,
+		},
+		{
+			name:            "unknown error type",
+			err:             errors.New("unexpected status code: 503"),
+			retryableErrors: []domain.WorkflowNodeRetryableErrorType{"unknown"},
+			want:            false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err, tt.retryableErrors); got != tt.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRetryDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  domain.WorkflowNodeRetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{
+			name:    "first retry",
+			policy:  domain.WorkflowNodeRetryPolicy{InitialDelay: 5, MaxDelay: 300, BackoffFactor: 2},
+			attempt: 1,
+			want:    5 * time.Second,
+		},
+		{
+			name:    "exponential backoff",
+			policy:  domain.WorkflowNodeRetryPolicy{InitialDelay: 5, MaxDelay: 300, BackoffFactor: 2},
+			attempt: 4,
+			want:    40 * time.Second,
+		},
+		{
+			name:    "capped by max delay",
+			policy:  domain.WorkflowNodeRetryPolicy{InitialDelay: 5, MaxDelay: 300, BackoffFactor: 2},
+			attempt: 10,
+			want:    300 * time.Second,
+		},
+		{
+			name:    "fractional backoff factor",
+			policy:  domain.WorkflowNodeRetryPolicy{InitialDelay: 10, MaxDelay: 300, BackoffFactor: 1.5},
+			attempt: 3,
+			want:    22500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRetryDelay(tt.policy, tt.attempt); got != tt.want {
+				t.Errorf("getRetryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ type workflowRepository interface {
 }
 
 type workflowRunRepository interface {
+	ListByStatus(ctx context.Context, status domain.WorkflowRunStatusType) ([]*domain.WorkflowRun, error)
 	GetById(ctx context.Context, id string) (*domain.WorkflowRun, error)
 	Save(ctx context.Context, workflowRun *domain.WorkflowRun) (*domain.WorkflowRun, error)
 }
@@ -57,8 +57,8 @@ func onWorkflowRecordCreateOrUpdate(ctx context.Context, record *core.Record) er
 	enabled := record.GetBool("enabled")
 	trigger := record.GetString("trigger")
 
-	// 如果是手动触发或未启用，移除定时任务
-	if !enabled || trigger == string(domain.WorkflowTriggerTypeManual) {
+	// 如果不是自动触发或未启用，移除定时任务
+	if !enabled || trigger != string(domain.WorkflowTriggerTypeAuto) {
 		scheduler.Remove(fmt.Sprintf("workflow#%s", workflowId))
 		return nil
 	}
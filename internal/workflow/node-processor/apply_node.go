@@ -2,9 +2,13 @@ package nodeprocessor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/exp/maps"
@@ -35,10 +39,16 @@ func NewApplyNode(node *domain.WorkflowNode) *applyNode {
 	}
 }
 
-func (n *applyNode) Process(ctx context.Context) error {
+func (n *applyNode) Process(ctx context.Context) (_err error) {
 	nodeCfg := n.node.GetConfigForApply()
 	n.logger.Info("ready to obtain certificate ...", slog.Any("config", nodeCfg))
 
+	// 支持同时申请多种算法的证书，以半角分号分隔
+	keyAlgorithms := getApplyKeyAlgorithms(nodeCfg)
+	if len(keyAlgorithms) > 1 && (nodeCfg.CSR != "" || nodeCfg.PrivateKey != "") {
+		return fmt.Errorf("multiple key algorithms are not supported when using a custom csr or private key")
+	}
+
 	// 查询上次执行结果
 	lastOutput, err := n.outputRepo.GetByNodeId(ctx, n.node.Id)
 	if err != nil && !domain.IsRecordNotFoundError(err) {
@@ -54,121 +64,379 @@ func (n *applyNode) Process(ctx context.Context) error {
 		n.logger.Info(fmt.Sprintf("re-apply, because %s", reason))
 	}
 
-	// 初始化申请器
-	applicant, err := applicant.NewWithWorkflowNode(applicant.ApplicantWithWorkflowNodeConfig{
-		Node:   n.node,
-		Logger: n.logger,
-	})
-	if err != nil {
-		n.logger.Warn("failed to create applicant provider")
-		return err
-	}
+	// 上次申请多种算法的证书部分失败时，复用已签发的证书，仅重新申请缺失的算法
+	reusableCertificates := n.getReusableCertificates(ctx, lastOutput)
 
-	// 申请证书
-	applyResult, err := applicant.Apply(ctx)
-	if err != nil {
-		n.logger.Warn("failed to obtain certificate")
-		return err
+	certificates := make([]*domain.Certificate, 0, len(keyAlgorithms))
+	ariReplacedKeyAlgorithms := make([]string, 0)
+	allIssued := false
+	defer func() {
+		// 申请多种算法的证书时，如果后续算法申请失败，仍需保存已签发的证书，以免浪费 CA 的速率限制配额
+		if _err != nil && !allIssued && len(certificates) > 0 {
+			n.logger.Warn(fmt.Sprintf("%d of %d certificate(s) issued before failure, saving them", len(certificates), len(keyAlgorithms)))
+			if err := n.saveCertificates(ctx, lastOutput, certificates, ariReplacedKeyAlgorithms, false); err != nil {
+				n.logger.Warn("failed to save node output")
+				_err = errors.Join(_err, err)
+			}
+		}
+	}()
+	for _, keyAlgorithm := range keyAlgorithms {
+		if len(keyAlgorithms) > 1 {
+			n.logger.Info(fmt.Sprintf("ready to obtain certificate with key algorithm '%s' ...", keyAlgorithm))
+		}
+
+		if certificate, ok := reusableCertificates[keyAlgorithm]; ok {
+			n.logger.Info(fmt.Sprintf("reuse the certificate with key algorithm '%s' issued in the last run", keyAlgorithm))
+			certificates = append(certificates, certificate)
+			continue
+		}
+
+		// 初始化申请器
+		applicantProvider, err := applicant.NewWithWorkflowNode(applicant.ApplicantWithWorkflowNodeConfig{
+			Node:         n.node,
+			Logger:       n.logger,
+			KeyAlgorithm: keyAlgorithm,
+		})
+		if err != nil {
+			n.logger.Warn("failed to create applicant provider")
+			return err
+		}
+
+		// 申请证书
+		applyResult, err := applicantProvider.Apply(ctx)
+		if err != nil {
+			var rlErr *applicant.RateLimitError
+			if errors.As(err, &rlErr) {
+				n.logger.Warn(fmt.Sprintf("certificate issuance refused by rate limit '%s' of ca '%s'", rlErr.Limit, rlErr.CAProvider), slog.Time("retryAt", rlErr.RetryAt))
+			} else {
+				n.logger.Warn("failed to obtain certificate")
+			}
+			return err
+		}
+
+		n.logger.Info(fmt.Sprintf("certificate issued by ca '%s'", applyResult.CAProvider))
+
+		// 解析证书并生成实体
+		certX509, err := xcert.ParseCertificateFromPEM(applyResult.FullChainCertificate)
+		if err != nil {
+			n.logger.Warn("failed to parse certificate, may be the CA responded error")
+			return err
+		}
+
+		certificate := &domain.Certificate{
+			Source:            domain.CertificateSourceTypeWorkflow,
+			Certificate:       applyResult.FullChainCertificate,
+			PrivateKey:        applyResult.PrivateKey,
+			IssuerCertificate: applyResult.IssuerCertificate,
+			ACMEAccountUrl:    applyResult.ACMEAccountUrl,
+			ACMECertUrl:       applyResult.ACMECertUrl,
+			ACMECertStableUrl: applyResult.ACMECertStableUrl,
+			KeyCreatedAt:      applyResult.PrivateKeyCreatedAt,
+			CAProvider:        applyResult.CAProvider,
+		}
+		certificate.PopulateFromX509(certX509)
+		certificates = append(certificates, certificate)
+
+		if applyResult.ARIReplaced {
+			ariReplacedKeyAlgorithms = append(ariReplacedKeyAlgorithms, keyAlgorithm)
+		}
 	}
 
-	// 解析证书并生成实体
-	certX509, err := xcert.ParseCertificateFromPEM(applyResult.FullChainCertificate)
-	if err != nil {
-		n.logger.Warn("failed to parse certificate, may be the CA responded error")
+	// 保存执行结果
+	allIssued = true
+	if err := n.saveCertificates(ctx, lastOutput, certificates, ariReplacedKeyAlgorithms, true); err != nil {
+		n.logger.Warn("failed to save node output")
 		return err
 	}
 
-	certificate := &domain.Certificate{
-		Source:            domain.CertificateSourceTypeWorkflow,
-		Certificate:       applyResult.FullChainCertificate,
-		PrivateKey:        applyResult.PrivateKey,
-		IssuerCertificate: applyResult.IssuerCertificate,
-		ACMEAccountUrl:    applyResult.ACMEAccountUrl,
-		ACMECertUrl:       applyResult.ACMECertUrl,
-		ACMECertStableUrl: applyResult.ACMECertStableUrl,
+	// 记录中间结果
+	daysLeft := math.MaxInt
+	for _, certificate := range certificates {
+		daysLeft = min(daysLeft, int(time.Until(certificate.ExpireAt).Hours()/24))
 	}
-	certificate.PopulateFromX509(certX509)
+	n.outputs[outputKeyForNodeSkipped] = strconv.FormatBool(false)
+	n.outputs[outputKeyForCertificateValidity] = strconv.FormatBool(true)
+	n.outputs[outputKeyForCertificateDaysLeft] = strconv.FormatInt(int64(daysLeft), 10)
 
-	// 保存执行结果
+	n.logger.Info("application completed")
+	return nil
+}
+
+func (n *applyNode) saveCertificates(ctx context.Context, lastOutput *domain.WorkflowOutput, certificates []*domain.Certificate, ariReplacedKeyAlgorithms []string, succeeded bool) error {
 	output := &domain.WorkflowOutput{
 		WorkflowId: getContextWorkflowId(ctx),
 		RunId:      getContextWorkflowRunId(ctx),
 		NodeId:     n.node.Id,
 		Node:       n.node,
-		Succeeded:  true,
+		Succeeded:  succeeded,
 		Outputs:    n.node.Outputs,
 	}
-	if _, err := n.outputRepo.SaveWithCertificate(ctx, output, certificate); err != nil {
-		n.logger.Warn("failed to save node output")
+	if _, err := n.outputRepo.SaveWithCertificates(ctx, output, certificates); err != nil {
 		return err
 	}
 
 	// 保存 ARI 记录
-	if applyResult.ARIReplaced && lastOutput != nil {
-		lastCertificate, _ := n.certRepo.GetByWorkflowRunIdAndNodeId(ctx, lastOutput.RunId, lastOutput.NodeId)
-		if lastCertificate != nil {
-			lastCertificate.ACMERenewed = true
-			n.certRepo.Save(ctx, lastCertificate)
+	if len(ariReplacedKeyAlgorithms) > 0 && lastOutput != nil {
+		keyAlgorithms := getApplyKeyAlgorithms(n.node.GetConfigForApply())
+		lastCertificates, _ := n.certRepo.ListByWorkflowRunIdAndNodeId(ctx, lastOutput.RunId, lastOutput.NodeId)
+		for _, keyAlgorithm := range ariReplacedKeyAlgorithms {
+			lastCertificate := pickCertificateByKeyAlgorithm(lastCertificates, keyAlgorithm, len(keyAlgorithms) == 1)
+			if lastCertificate != nil {
+				lastCertificate.ACMERenewed = true
+				n.certRepo.Save(ctx, lastCertificate)
+			}
 		}
 	}
 
-	// 记录中间结果
-	n.outputs[outputKeyForNodeSkipped] = strconv.FormatBool(false)
-	n.outputs[outputKeyForCertificateValidity] = strconv.FormatBool(true)
-	n.outputs[outputKeyForCertificateDaysLeft] = strconv.FormatInt(int64(time.Until(certificate.ExpireAt).Hours()/24), 10)
-
-	n.logger.Info("application completed")
 	return nil
 }
 
 func (n *applyNode) checkCanSkip(ctx context.Context, lastOutput *domain.WorkflowOutput) (_skip bool, _reason string) {
 	if lastOutput != nil && lastOutput.Succeeded {
-		// 比较和上次申请时的关键配置（即影响证书签发的）参数是否一致
-		thisNodeCfg := n.node.GetConfigForApply()
-		lastNodeCfg := lastOutput.Node.GetConfigForApply()
+		if reusable, reason := n.checkLastOutputCanReuse(ctx, lastOutput); !reusable {
+			return false, reason
+		}
+
+		// 每种算法的证书均无需续期时，才可以跳过
+		keyAlgorithms := getApplyKeyAlgorithms(n.node.GetConfigForApply())
+		lastCertificates, _ := n.certRepo.ListByWorkflowRunIdAndNodeId(ctx, lastOutput.RunId, lastOutput.NodeId)
+		reasons := make([]string, 0, len(keyAlgorithms))
+		minDaysLeft := math.MaxInt
+		for _, keyAlgorithm := range keyAlgorithms {
+			lastCertificate := pickCertificateByKeyAlgorithm(lastCertificates, keyAlgorithm, len(keyAlgorithms) == 1)
+			if lastCertificate == nil {
+				return false, ""
+			}
+
+			skippable, reason := n.checkCertificateCanSkip(ctx, lastCertificate)
+			if len(keyAlgorithms) > 1 {
+				reason = fmt.Sprintf("[%s] %s", keyAlgorithm, reason)
+			}
+			if !skippable {
+				return false, reason
+			}
 
-		if thisNodeCfg.Domains != lastNodeCfg.Domains {
-			return false, "the configuration item 'Domains' changed"
+			reasons = append(reasons, reason)
+			minDaysLeft = min(minDaysLeft, int(time.Until(lastCertificate.ExpireAt).Hours()/24))
 		}
-		if thisNodeCfg.ContactEmail != lastNodeCfg.ContactEmail {
-			return false, "the configuration item 'ContactEmail' changed"
+
+		// TODO: 优化此处逻辑，[checkCanSkip] 方法不应该修改中间结果，违背单一职责
+		n.outputs[outputKeyForCertificateValidity] = strconv.FormatBool(true)
+		n.outputs[outputKeyForCertificateDaysLeft] = strconv.FormatInt(int64(minDaysLeft), 10)
+
+		return true, strings.Join(reasons, "; ")
+	}
+
+	return false, ""
+}
+
+func (n *applyNode) checkLastOutputCanReuse(ctx context.Context, lastOutput *domain.WorkflowOutput) (_reusable bool, _reason string) {
+	// 比较和上次申请时的关键配置（即影响证书签发的）参数是否一致
+	thisNodeCfg := n.node.GetConfigForApply()
+	lastNodeCfg := lastOutput.Node.GetConfigForApply()
+
+	if thisNodeCfg.Domains != lastNodeCfg.Domains {
+		return false, "the configuration item 'Domains' changed"
+	}
+	if thisNodeCfg.ContactEmail != lastNodeCfg.ContactEmail {
+		return false, "the configuration item 'ContactEmail' changed"
+	}
+	if thisNodeCfg.ChallengeType != lastNodeCfg.ChallengeType {
+		return false, "the configuration item 'ChallengeType' changed"
+	}
+	if thisNodeCfg.Provider != lastNodeCfg.Provider {
+		return false, "the configuration item 'Provider' changed"
+	}
+	if thisNodeCfg.ProviderAccessId != lastNodeCfg.ProviderAccessId {
+		return false, "the configuration item 'ProviderAccessId' changed"
+	}
+	if !maps.Equal(thisNodeCfg.ProviderConfig, lastNodeCfg.ProviderConfig) {
+		return false, "the configuration item 'ProviderConfig' changed"
+	}
+	if thisNodeCfg.CAProvider != lastNodeCfg.CAProvider {
+		return false, "the configuration item 'CAProvider' changed"
+	}
+	if thisNodeCfg.CAProviderAccessId != lastNodeCfg.CAProviderAccessId {
+		return false, "the configuration item 'CAProviderAccessId' changed"
+	}
+	if !maps.Equal(thisNodeCfg.CAProviderConfig, lastNodeCfg.CAProviderConfig) {
+		return false, "the configuration item 'CAProviderConfig' changed"
+	}
+	if !slices.EqualFunc(thisNodeCfg.CAProviderFallbacks, lastNodeCfg.CAProviderFallbacks, func(a, b domain.WorkflowNodeConfigForApplyCAFallback) bool {
+		return a.CAProvider == b.CAProvider && a.CAProviderAccessId == b.CAProviderAccessId && maps.Equal(a.CAProviderConfig, b.CAProviderConfig)
+	}) {
+		return false, "the configuration item 'CAProviderFallbacks' changed"
+	}
+	if thisNodeCfg.KeyAlgorithm != lastNodeCfg.KeyAlgorithm {
+		return false, "the configuration item 'KeyAlgorithm' changed"
+	}
+	if thisNodeCfg.CSR != lastNodeCfg.CSR {
+		return false, "the configuration item 'CSR' changed"
+	}
+	if thisNodeCfg.PrivateKey != lastNodeCfg.PrivateKey {
+		return false, "the configuration item 'PrivateKey' changed"
+	}
+	if thisNodeCfg.ReuseKey != lastNodeCfg.ReuseKey {
+		return false, "the configuration item 'ReuseKey' changed"
+	}
+	if thisNodeCfg.ReuseKeyMaxAge != lastNodeCfg.ReuseKeyMaxAge {
+		return false, "the configuration item 'ReuseKeyMaxAge' changed"
+	}
+
+	// 由证书事件触发时，若事件指向本节点，则强制重新申请
+	runPayload := getContextWorkflowRunPayload(ctx)
+	if event, _ := runPayload["event"].(string); event != "" {
+		if nodeId, _ := runPayload["workflowNodeId"].(string); nodeId == n.node.Id {
+			return false, fmt.Sprintf("the certificate event '%s' was triggered", event)
 		}
-		if thisNodeCfg.Provider != lastNodeCfg.Provider {
-			return false, "the configuration item 'Provider' changed"
+	}
+
+	return true, ""
+}
+
+func (n *applyNode) getReusableCertificates(ctx context.Context, lastOutput *domain.WorkflowOutput) map[string]*domain.Certificate {
+	reusableCertificates := make(map[string]*domain.Certificate)
+
+	keyAlgorithms := getApplyKeyAlgorithms(n.node.GetConfigForApply())
+	if lastOutput == nil || lastOutput.Succeeded || len(keyAlgorithms) <= 1 {
+		return reusableCertificates
+	}
+
+	if reusable, _ := n.checkLastOutputCanReuse(ctx, lastOutput); !reusable {
+		return reusableCertificates
+	}
+
+	lastCertificates, err := n.certRepo.ListByWorkflowRunIdAndNodeId(ctx, lastOutput.RunId, lastOutput.NodeId)
+	if err != nil {
+		return reusableCertificates
+	}
+
+	for _, keyAlgorithm := range keyAlgorithms {
+		lastCertificate := pickCertificateByKeyAlgorithm(lastCertificates, keyAlgorithm, false)
+		if lastCertificate == nil {
+			continue
 		}
-		if thisNodeCfg.ProviderAccessId != lastNodeCfg.ProviderAccessId {
-			return false, "the configuration item 'ProviderAccessId' changed"
+
+		if skippable, _ := n.checkCertificateCanSkip(ctx, lastCertificate); !skippable {
+			continue
 		}
-		if !maps.Equal(thisNodeCfg.ProviderConfig, lastNodeCfg.ProviderConfig) {
-			return false, "the configuration item 'ProviderConfig' changed"
+
+		// 复用的证书需关联到本次执行结果，因此另存为新的记录
+		certificate := *lastCertificate
+		certificate.Meta = domain.Meta{}
+		certificate.WorkflowId = ""
+		certificate.WorkflowRunId = ""
+		certificate.WorkflowNodeId = ""
+		certificate.WorkflowOutputId = ""
+		reusableCertificates[keyAlgorithm] = &certificate
+	}
+
+	return reusableCertificates
+}
+
+func (n *applyNode) checkCertificateCanSkip(ctx context.Context, lastCertificate *domain.Certificate) (_skip bool, _reason string) {
+	thisNodeCfg := n.node.GetConfigForApply()
+
+	if lastCertificate.IsRevoked() {
+		return false, "the last certificate has been revoked"
+	}
+
+	if !thisNodeCfg.DisableARI {
+		// 优先按照 CA 建议的 ARI 续期窗口决定是否续期，不可用时再按照剩余天数判断
+		if windowStart, windowEnd, renewAt, ok := n.getRenewalWindow(ctx, lastCertificate); ok {
+			daysLeft := int(time.Until(lastCertificate.ExpireAt).Hours() / 24)
+
+			now := time.Now()
+			if !now.Before(windowEnd) {
+				// 续期窗口已结束（通常意味着 CA 将要批量吊销证书），立即续期
+				return false, "the renewal window suggested by ARI has passed, the certificate may be revoked soon"
+			}
+			if !now.Before(renewAt) {
+				return false, fmt.Sprintf("the renewal window suggested by ARI has opened (%s ~ %s)", windowStart.UTC().Format(time.RFC3339), windowEnd.UTC().Format(time.RFC3339))
+			}
+
+			return true, fmt.Sprintf("the certificate has already been issued (expires in %d day(s), renewal window suggested by ARI opens at %s, next renewal at %s)", daysLeft, windowStart.UTC().Format(time.RFC3339), renewAt.UTC().Format(time.RFC3339))
 		}
-		if thisNodeCfg.CAProvider != lastNodeCfg.CAProvider {
-			return false, "the configuration item 'CAProvider' changed"
+	}
+
+	renewalInterval := time.Duration(thisNodeCfg.SkipBeforeExpiryDays) * time.Hour * 24
+	expirationTime := time.Until(lastCertificate.ExpireAt)
+	if expirationTime > renewalInterval {
+		daysLeft := int(expirationTime.Hours() / 24)
+		return true, fmt.Sprintf("the certificate has already been issued (expires in %d day(s), next renewal in %d day(s))", daysLeft, thisNodeCfg.SkipBeforeExpiryDays)
+	}
+
+	return false, ""
+}
+
+func (n *applyNode) getRenewalWindow(ctx context.Context, certificate *domain.Certificate) (_start time.Time, _end time.Time, _renewAt time.Time, _ok bool) {
+	if certificate.ACMEAccountUrl == "" || certificate.ACMERenewed {
+		return time.Time{}, time.Time{}, time.Time{}, false
+	}
+
+	renewalInfo, err := applicant.GetRenewalInfo(ctx, certificate)
+	if err != nil {
+		if !errors.Is(err, applicant.ErrNoARI) {
+			n.logger.Warn(fmt.Sprintf("failed to get acme renewal info: %s", err.Error()))
 		}
-		if thisNodeCfg.CAProviderAccessId != lastNodeCfg.CAProviderAccessId {
-			return false, "the configuration item 'CAProviderAccessId' changed"
+
+		// 查询失败时，使用上次持久化的续期窗口
+		if !certificate.ACMERenewalWindowStart.IsZero() && !certificate.ACMERenewalWindowEnd.IsZero() {
+			if certificate.UpdateACMERenewalWindow(certificate.ACMERenewalWindowStart, certificate.ACMERenewalWindowEnd) {
+				if _, err := n.certRepo.Save(ctx, certificate); err != nil {
+					n.logger.Warn(fmt.Sprintf("failed to save acme renewal window: %s", err.Error()))
+				}
+			}
+			return certificate.ACMERenewalWindowStart, certificate.ACMERenewalWindowEnd, certificate.ACMERenewalTime, true
 		}
-		if !maps.Equal(thisNodeCfg.CAProviderConfig, lastNodeCfg.CAProviderConfig) {
-			return false, "the configuration item 'CAProviderConfig' changed"
+		return time.Time{}, time.Time{}, time.Time{}, false
+	}
+
+	if renewalInfo.WindowStart.IsZero() || renewalInfo.WindowEnd.IsZero() || renewalInfo.WindowEnd.Before(renewalInfo.WindowStart) {
+		return time.Time{}, time.Time{}, time.Time{}, false
+	}
+
+	// 持久化续期窗口及在其中选取的续期时间
+	if certificate.UpdateACMERenewalWindow(renewalInfo.WindowStart, renewalInfo.WindowEnd) {
+		if _, err := n.certRepo.Save(ctx, certificate); err != nil {
+			n.logger.Warn(fmt.Sprintf("failed to save acme renewal window: %s", err.Error()))
 		}
-		if thisNodeCfg.KeyAlgorithm != lastNodeCfg.KeyAlgorithm {
-			return false, "the configuration item 'KeyAlgorithm' changed"
+	}
+
+	if renewalInfo.ExplanationURL != "" {
+		n.logger.Info(fmt.Sprintf("the ca provided an explanation of the renewal window: %s", renewalInfo.ExplanationURL))
+	}
+
+	return certificate.ACMERenewalWindowStart, certificate.ACMERenewalWindowEnd, certificate.ACMERenewalTime, true
+}
+
+func getApplyKeyAlgorithms(nodeCfg domain.WorkflowNodeConfigForApply) []string {
+	keyAlgorithms := make([]string, 0)
+	for _, keyAlgorithm := range strings.Split(nodeCfg.KeyAlgorithm, ";") {
+		keyAlgorithm = strings.TrimSpace(keyAlgorithm)
+		if keyAlgorithm != "" && !slices.Contains(keyAlgorithms, keyAlgorithm) {
+			keyAlgorithms = append(keyAlgorithms, keyAlgorithm)
 		}
+	}
 
-		lastCertificate, _ := n.certRepo.GetByWorkflowRunIdAndNodeId(ctx, lastOutput.RunId, lastOutput.NodeId)
-		if lastCertificate != nil {
-			renewalInterval := time.Duration(thisNodeCfg.SkipBeforeExpiryDays) * time.Hour * 24
-			expirationTime := time.Until(lastCertificate.ExpireAt)
-			if expirationTime > renewalInterval {
-				daysLeft := int(expirationTime.Hours() / 24)
-				// TODO: 优化此处逻辑，[checkCanSkip] 方法不应该修改中间结果，违背单一职责
-				n.outputs[outputKeyForCertificateValidity] = strconv.FormatBool(true)
-				n.outputs[outputKeyForCertificateDaysLeft] = strconv.FormatInt(int64(daysLeft), 10)
+	if len(keyAlgorithms) == 0 {
+		keyAlgorithms = append(keyAlgorithms, string(domain.CertificateKeyAlgorithmTypeRSA2048))
+	}
 
-				return true, fmt.Sprintf("the certificate has already been issued (expires in %d day(s), next renewal in %d day(s))", daysLeft, thisNodeCfg.SkipBeforeExpiryDays)
-			}
+	return keyAlgorithms
+}
+
+func pickCertificateByKeyAlgorithm(certificates []*domain.Certificate, keyAlgorithm string, fallback bool) *domain.Certificate {
+	for _, certificate := range certificates {
+		if string(certificate.KeyAlgorithm) == keyAlgorithm {
+			return certificate
 		}
 	}
 
-	return false, ""
+	// 仅申请单一算法的证书时，兼容证书算法与配置不一致的情况（如使用自定义 CSR）
+	if fallback && len(certificates) > 0 {
+		return certificates[0]
+	}
+
+	return nil
 }
@@ -1,7 +1,13 @@
 package nodeprocessor
 
 const (
-	outputKeyForCertificateValidity = "certificate.validity"
-	outputKeyForCertificateDaysLeft = "certificate.daysLeft"
-	outputKeyForNodeSkipped         = "node.skipped"
+	outputKeyForCertificateValidity  = "certificate.validity"
+	outputKeyForCertificateDaysLeft  = "certificate.daysLeft"
+	outputKeyForNodeSkipped          = "node.skipped"
+	outputKeyPrefixForTriggerPayload = "trigger.payload."
+)
+
+const (
+	// 节点实际执行的尝试次数，由执行器在节点执行完成后写入
+	OutputKeyForNodeAttempts = "node.attempts"
 )
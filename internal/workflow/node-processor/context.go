@@ -34,6 +34,16 @@ func getNodeOutputsContainer(ctx context.Context) *nodeOutputsContainer {
 	return value.(*nodeOutputsContainer)
 }
 
+// 初始化节点输出容器
+// 并行分支共享同一个容器，以便各分支的节点输出能合并回工作流上下文
+func InitNodeOutputs(ctx context.Context) context.Context {
+	if getNodeOutputsContainer(ctx) != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, nodeOutputsKey, newNodeOutputsContainer())
+}
+
 // 添加节点输出到上下文
 func AddNodeOutput(ctx context.Context, nodeId string, output map[string]any) context.Context {
 	container := getNodeOutputsContainer(ctx)
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
-	"strings"
 
 	"golang.org/x/exp/maps"
 
@@ -45,14 +44,8 @@ func (n *deployNode) Process(ctx context.Context) error {
 	}
 
 	// 获取前序节点输出证书
-	const DELIMITER = "#"
 	previousNodeOutputCertificateSource := n.node.GetConfigForDeploy().Certificate
-	previousNodeOutputCertificateSourceSlice := strings.Split(previousNodeOutputCertificateSource, DELIMITER)
-	if len(previousNodeOutputCertificateSourceSlice) != 2 {
-		n.logger.Warn("invalid certificate source", slog.String("certificate.source", previousNodeOutputCertificateSource))
-		return fmt.Errorf("invalid certificate source: %s", previousNodeOutputCertificateSource)
-	}
-	certificate, err := n.certRepo.GetByWorkflowNodeId(ctx, previousNodeOutputCertificateSourceSlice[0])
+	certificate, err := getCertificateBySource(ctx, n.certRepo, n.outputRepo, previousNodeOutputCertificateSource)
 	if err != nil {
 		n.logger.Warn("invalid certificate source", slog.String("certificate.source", previousNodeOutputCertificateSource))
 		return err
@@ -113,6 +106,9 @@ func (n *deployNode) checkCanSkip(ctx context.Context, lastOutput *domain.Workfl
 		thisNodeCfg := n.node.GetConfigForDeploy()
 		lastNodeCfg := lastOutput.Node.GetConfigForDeploy()
 
+		if thisNodeCfg.Certificate != lastNodeCfg.Certificate {
+			return false, "the configuration item 'Certificate' changed"
+		}
 		if thisNodeCfg.ProviderAccessId != lastNodeCfg.ProviderAccessId {
 			return false, "the configuration item 'ProviderAccessId' changed"
 		}
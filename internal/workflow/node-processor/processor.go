@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"strings"
 
 	"github.com/certimate-go/certimate/internal/domain"
 )
@@ -50,7 +51,9 @@ func (n *nodeOutputer) GetOutputs() map[string]any {
 
 type certificateRepository interface {
 	GetByWorkflowNodeId(ctx context.Context, workflowNodeId string) (*domain.Certificate, error)
+	GetByWorkflowNodeIdAndKeyAlgorithm(ctx context.Context, workflowNodeId string, keyAlgorithm domain.CertificateKeyAlgorithmType) (*domain.Certificate, error)
 	GetByWorkflowRunIdAndNodeId(ctx context.Context, workflowRunId string, workflowNodeId string) (*domain.Certificate, error)
+	ListByWorkflowRunIdAndNodeId(ctx context.Context, workflowRunId string, workflowNodeId string) ([]*domain.Certificate, error)
 	Save(ctx context.Context, certificate *domain.Certificate) (*domain.Certificate, error)
 }
 
@@ -58,6 +61,7 @@ type workflowOutputRepository interface {
 	GetByNodeId(ctx context.Context, workflowNodeId string) (*domain.WorkflowOutput, error)
 	Save(ctx context.Context, workflowOutput *domain.WorkflowOutput) (*domain.WorkflowOutput, error)
 	SaveWithCertificate(ctx context.Context, workflowOutput *domain.WorkflowOutput, certificate *domain.Certificate) (*domain.WorkflowOutput, error)
+	SaveWithCertificates(ctx context.Context, workflowOutput *domain.WorkflowOutput, certificates []*domain.Certificate) (*domain.WorkflowOutput, error)
 }
 
 type settingsRepository interface {
@@ -82,6 +86,8 @@ func GetProcessor(node *domain.WorkflowNode) (NodeProcessor, error) {
 		return NewMonitorNode(node), nil
 	case domain.WorkflowNodeTypeDeploy:
 		return NewDeployNode(node), nil
+	case domain.WorkflowNodeTypeRevoke:
+		return NewRevokeNode(node), nil
 	case domain.WorkflowNodeTypeNotify:
 		return NewNotifyNode(node), nil
 	case domain.WorkflowNodeTypeCondition:
@@ -102,3 +108,31 @@ func getContextWorkflowId(ctx context.Context) string {
 func getContextWorkflowRunId(ctx context.Context) string {
 	return ctx.Value("workflow_run_id").(string)
 }
+
+func getContextWorkflowRunPayload(ctx context.Context) map[string]any {
+	payload, _ := ctx.Value("workflow_run_payload").(map[string]any)
+	return payload
+}
+
+// 根据前序节点输出的证书来源获取证书。
+// 来源形如 "${NodeId}#certificate"；对于申请了多种算法证书的节点，可使用 "${NodeId}#certificate:${KeyAlgorithm}" 指定算法。
+func getCertificateBySource(ctx context.Context, certRepo certificateRepository, outputRepo workflowOutputRepository, source string) (*domain.Certificate, error) {
+	const DELIMITER = "#"
+	nodeId, outputName, ok := strings.Cut(source, DELIMITER)
+	if !ok || nodeId == "" || strings.Contains(outputName, DELIMITER) {
+		return nil, fmt.Errorf("invalid certificate source: %s", source)
+	}
+
+	if _, keyAlgorithm, ok := strings.Cut(outputName, ":"); ok {
+		return certRepo.GetByWorkflowNodeIdAndKeyAlgorithm(ctx, nodeId, domain.CertificateKeyAlgorithmType(keyAlgorithm))
+	}
+
+	// 未指定算法时，使用节点配置的第一种算法的证书
+	if lastOutput, _ := outputRepo.GetByNodeId(ctx, nodeId); lastOutput != nil && lastOutput.Node != nil && lastOutput.Node.Type == domain.WorkflowNodeTypeApply {
+		if keyAlgorithms := getApplyKeyAlgorithms(lastOutput.Node.GetConfigForApply()); len(keyAlgorithms) > 1 {
+			return certRepo.GetByWorkflowNodeIdAndKeyAlgorithm(ctx, nodeId, domain.CertificateKeyAlgorithmType(keyAlgorithms[0]))
+		}
+	}
+
+	return certRepo.GetByWorkflowNodeId(ctx, nodeId)
+}
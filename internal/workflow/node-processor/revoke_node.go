@@ -0,0 +1,87 @@
+package nodeprocessor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/applicant"
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/repository"
+)
+
+type revokeNode struct {
+	node *domain.WorkflowNode
+	*nodeProcessor
+	*nodeOutputer
+
+	certRepo   certificateRepository
+	outputRepo workflowOutputRepository
+}
+
+func NewRevokeNode(node *domain.WorkflowNode) *revokeNode {
+	return &revokeNode{
+		node:          node,
+		nodeProcessor: newNodeProcessor(node),
+		nodeOutputer:  newNodeOutputer(),
+
+		certRepo:   repository.NewCertificateRepository(),
+		outputRepo: repository.NewWorkflowOutputRepository(),
+	}
+}
+
+func (n *revokeNode) Process(ctx context.Context) error {
+	nodeCfg := n.node.GetConfigForRevoke()
+	n.logger.Info("ready to revoke certificate ...", slog.Any("config", nodeCfg))
+
+	// 获取前序节点输出证书
+	previousNodeOutputCertificateSource := nodeCfg.Certificate
+	certificate, err := getCertificateBySource(ctx, n.certRepo, n.outputRepo, previousNodeOutputCertificateSource)
+	if err != nil {
+		n.logger.Warn("invalid certificate source", slog.String("certificate.source", previousNodeOutputCertificateSource))
+		return err
+	}
+
+	// 已吊销的证书无需重复吊销
+	if certificate.IsRevoked() {
+		n.outputs[outputKeyForNodeSkipped] = strconv.FormatBool(true)
+		n.logger.Info(fmt.Sprintf("skip this revocation, because the certificate has already been revoked at %s", certificate.RevokedAt.UTC().Format(time.RFC3339)))
+		return nil
+	}
+
+	// 吊销证书
+	reason := domain.CertificateRevocationReasonType(nodeCfg.Reason)
+	if err := applicant.RevokeCertificate(ctx, certificate, reason); err != nil {
+		n.logger.Warn("failed to revoke certificate")
+		return err
+	}
+
+	certificate.RevokedAt = time.Now()
+	certificate.RevocationReason = reason
+	if _, err := n.certRepo.Save(ctx, certificate); err != nil {
+		n.logger.Warn("failed to save certificate")
+		return err
+	}
+
+	// 保存执行结果
+	output := &domain.WorkflowOutput{
+		WorkflowId: getContextWorkflowId(ctx),
+		RunId:      getContextWorkflowRunId(ctx),
+		NodeId:     n.node.Id,
+		Node:       n.node,
+		Succeeded:  true,
+	}
+	if _, err := n.outputRepo.Save(ctx, output); err != nil {
+		n.logger.Warn("failed to save node output")
+		return err
+	}
+
+	// 记录中间结果
+	n.outputs[outputKeyForNodeSkipped] = strconv.FormatBool(false)
+	n.outputs[outputKeyForCertificateValidity] = strconv.FormatBool(false)
+
+	n.logger.Info("revocation completed")
+	return nil
+}
@@ -2,6 +2,8 @@ package nodeprocessor
 
 import (
 	"context"
+	"encoding/json"
+	"strconv"
 
 	"github.com/certimate-go/certimate/internal/domain"
 )
@@ -24,5 +26,31 @@ func (n *startNode) Process(ctx context.Context) error {
 	// 此类型节点不需要执行任何操作，直接返回
 	n.logger.Info("workflow is started")
 
+	// 将触发时携带的数据（如 Webhook 请求体）作为变量输出，供后续节点使用
+	if payload := getContextWorkflowRunPayload(ctx); len(payload) > 0 {
+		n.flattenPayload(outputKeyPrefixForTriggerPayload, payload)
+	}
+
 	return nil
 }
+
+func (n *startNode) flattenPayload(prefix string, payload map[string]any) {
+	for key, value := range payload {
+		switch v := value.(type) {
+		case map[string]any:
+			n.flattenPayload(prefix+key+".", v)
+		case nil:
+			continue
+		case string:
+			n.outputs[prefix+key] = v
+		case bool:
+			n.outputs[prefix+key] = strconv.FormatBool(v)
+		case float64:
+			n.outputs[prefix+key] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			if bytes, err := json.Marshal(v); err == nil {
+				n.outputs[prefix+key] = string(bytes)
+			}
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package workflow
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pocketbase/dbx"
@@ -78,6 +84,11 @@ func (s *WorkflowService) InitSchedule(ctx context.Context) error {
 		}
 	})
 
+	// 恢复上次退出时未完成的工作流执行
+	if err := s.dispatcher.Recover(ctx); err != nil {
+		app.GetLogger().Error("failed to recover workflow runs", "err", err)
+	}
+
 	// 工作流
 	{
 		workflows, err := s.workflowRepo.ListEnabledAuto(ctx)
@@ -113,8 +124,57 @@ func (s *WorkflowService) StartRun(ctx context.Context, req *dtos.WorkflowStartR
 		return err
 	}
 
+	_, err = s.startRun(ctx, workflow, req)
+	return err
+}
+
+func (s *WorkflowService) TriggerWebhook(ctx context.Context, req *dtos.WorkflowTriggerWebhookReq) (*dtos.WorkflowTriggerWebhookResp, error) {
+	workflow, err := s.workflowRepo.GetById(ctx, req.WorkflowId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未启用 Webhook 触发或令牌不匹配时，均视为不存在，避免泄露工作流信息
+	if !workflow.Enabled || workflow.Trigger != domain.WorkflowTriggerTypeWebhook || workflow.Content == nil {
+		return nil, domain.ErrRecordNotFound
+	}
+
+	startCfg := workflow.Content.GetConfigForStart()
+	if startCfg.WebhookToken == "" || subtle.ConstantTimeCompare([]byte(startCfg.WebhookToken), []byte(req.Token)) != 1 {
+		return nil, domain.ErrRecordNotFound
+	}
+
+	if startCfg.WebhookSecret != "" {
+		if !verifyWebhookSignature(startCfg.WebhookSecret, req.Body, req.Signature) {
+			return nil, domain.NewError(401, "invalid signature")
+		}
+	}
+
+	// 解析请求体，仅支持 JSON 对象
+	var payload map[string]any
+	if len(bytes.TrimSpace(req.Body)) > 0 {
+		if err := json.Unmarshal(req.Body, &payload); err != nil {
+			return nil, domain.NewError(400, "invalid payload, must be a json object")
+		}
+	}
+
+	run, err := s.startRun(ctx, workflow, &dtos.WorkflowStartRunReq{
+		WorkflowId: workflow.Id,
+		RunTrigger: domain.WorkflowTriggerTypeWebhook,
+		RunPayload: payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.WorkflowTriggerWebhookResp{
+		RunId: run.Id,
+	}, nil
+}
+
+func (s *WorkflowService) startRun(ctx context.Context, workflow *domain.Workflow, req *dtos.WorkflowStartRunReq) (*domain.WorkflowRun, error) {
 	if workflow.LastRunStatus == domain.WorkflowRunStatusTypePending || workflow.LastRunStatus == domain.WorkflowRunStatusTypeRunning {
-		return errors.New("workflow is already pending or running")
+		return nil, errors.New("workflow is already pending or running")
 	}
 
 	run := &domain.WorkflowRun{
@@ -123,9 +183,10 @@ func (s *WorkflowService) StartRun(ctx context.Context, req *dtos.WorkflowStartR
 		Trigger:    req.RunTrigger,
 		StartedAt:  time.Now(),
 		Detail:     workflow.Content,
+		Payload:    req.RunPayload,
 	}
 	if resp, err := s.workflowRunRepo.Save(ctx, run); err != nil {
-		return err
+		return nil, err
 	} else {
 		run = resp
 	}
@@ -134,9 +195,10 @@ func (s *WorkflowService) StartRun(ctx context.Context, req *dtos.WorkflowStartR
 		WorkflowId:      run.WorkflowId,
 		WorkflowContent: run.Detail,
 		RunId:           run.Id,
+		RunPayload:      run.Payload,
 	})
 
-	return nil
+	return run, nil
 }
 
 func (s *WorkflowService) CancelRun(ctx context.Context, req *dtos.WorkflowCancelRunReq) error {
@@ -160,5 +222,13 @@ func (s *WorkflowService) CancelRun(ctx context.Context, req *dtos.WorkflowCance
 }
 
 func (s *WorkflowService) Shutdown(ctx context.Context) {
-	s.dispatcher.Shutdown()
+	s.dispatcher.Shutdown(ctx)
+}
+
+// 校验 HMAC-SHA256 签名，形如 "sha256=<hex>"
+func verifyWebhookSignature(secret string, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
 }
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"testing"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		body      string
+		signature string
+		want      bool
+	}{
+		{
+			name:      "rfc 4231 test case 2",
+			secret:    "Jefe",
+			body:      "what do ya want for nothing?",
+			signature: "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+			want:      true,
+		},
+		{
+			name:      "github webhook example",
+			secret:    "It's a Secret to Everybody",
+			body:      "Hello, World!",
+			signature: "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+			want:      true,
+		},
+		{
+			name:      "uppercase hex digest",
+			secret:    "Jefe",
+			body:      "what do ya want for nothing?",
+			signature: "SHA256=5BDCC146BF60754E6A042426089575C75A003F089D2739839DEC58B964EC3843",
+			want:      true,
+		},
+		{
+			name:      "tampered body",
+			secret:    "Jefe",
+			body:      "what do ya want for nothing!",
+			signature: "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+			want:      false,
+		},
+		{
+			name:      "wrong secret",
+			secret:    "jefe",
+			body:      "what do ya want for nothing?",
+			signature: "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+			want:      false,
+		},
+		{
+			name:      "missing prefix",
+			secret:    "Jefe",
+			body:      "what do ya want for nothing?",
+			signature: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+			want:      false,
+		},
+		{
+			name:      "empty signature",
+			secret:    "Jefe",
+			body:      "what do ya want for nothing?",
+			signature: "",
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyWebhookSignature(tt.secret, []byte(tt.body), tt.signature); got != tt.want {
+				t.Errorf("verifyWebhookSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		tracer := NewTracer("(v0.3)1752566400")
+		tracer.Printf("go ...")
+
+		// update collection `workflow`
+		{
+			collection, err := app.FindCollectionByNameOrId("tovyif5ax6j62ur")
+			if err != nil {
+				return err
+			}
+
+			// update field
+			if err := collection.Fields.AddMarshaledJSONAt(3, []byte(`{
+				"hidden": false,
+				"id": "vqoajwjq",
+				"maxSelect": 1,
+				"name": "trigger",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "select",
+				"values": [
+					"auto",
+					"manual",
+					"webhook"
+				]
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		// update collection `workflow_run`
+		{
+			collection, err := app.FindCollectionByNameOrId("qjp8lygssgwyqyz")
+			if err != nil {
+				return err
+			}
+
+			// update field
+			if err := collection.Fields.AddMarshaledJSONAt(3, []byte(`{
+				"hidden": false,
+				"id": "jlroa3fk",
+				"maxSelect": 1,
+				"name": "trigger",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "select",
+				"values": [
+					"auto",
+					"manual",
+					"webhook"
+				]
+			}`)); err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSONAt(8, []byte(`{
+				"hidden": false,
+				"id": "json1110206997",
+				"maxSize": 1000000,
+				"name": "payload",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "json"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		tracer.Printf("done")
+		return nil
+	}, func(app core.App) error {
+		return nil
+	})
+}
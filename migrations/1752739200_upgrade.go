@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		tracer := NewTracer("(v0.3)1752739200")
+		tracer.Printf("go ...")
+
+		// update collection `certificate`
+		{
+			collection, err := app.FindCollectionByNameOrId("4szxr9x43tpj6np")
+			if err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSONAt(15, []byte(`{
+				"hidden": false,
+				"id": "date2016405717",
+				"max": "",
+				"min": "",
+				"name": "acmeRenewalWindowStart",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "date"
+			}`)); err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSONAt(16, []byte(`{
+				"hidden": false,
+				"id": "date3626478504",
+				"max": "",
+				"min": "",
+				"name": "acmeRenewalWindowEnd",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "date"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		tracer.Printf("done")
+		return nil
+	}, func(app core.App) error {
+		return nil
+	})
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		tracer := NewTracer("(v0.3)1752825600")
+		tracer.Printf("go ...")
+
+		// update collection `certificate`
+		{
+			collection, err := app.FindCollectionByNameOrId("4szxr9x43tpj6np")
+			if err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSONAt(17, []byte(`{
+				"hidden": false,
+				"id": "date2472898396",
+				"max": "",
+				"min": "",
+				"name": "revokedAt",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "date"
+			}`)); err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSONAt(18, []byte(`{
+				"hidden": false,
+				"id": "number1796414512",
+				"max": 10,
+				"min": 0,
+				"name": "revocationReason",
+				"onlyInt": true,
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "number"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		tracer.Printf("done")
+		return nil
+	}, func(app core.App) error {
+		return nil
+	})
+}
@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		tracer := NewTracer("(v0.3)1753257600")
+		tracer.Printf("go ...")
+
+		// create collection `acme_rate_limits`
+		{
+			collection := core.NewBaseCollection("acme_rate_limits", "9kq2hx7mrb4tz1a")
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSON([]byte(`{
+				"autogeneratePattern": "",
+				"hidden": false,
+				"id": "text2324736937",
+				"max": 0,
+				"min": 0,
+				"name": "key",
+				"pattern": "",
+				"presentable": false,
+				"primaryKey": false,
+				"required": true,
+				"system": false,
+				"type": "text"
+			}`)); err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSON([]byte(`{
+				"hidden": false,
+				"id": "date1019283746",
+				"max": "",
+				"min": "",
+				"name": "blockedUntil",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "date"
+			}`)); err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSON([]byte(`{
+				"hidden": false,
+				"id": "json3458201746",
+				"maxSize": 0,
+				"name": "failedValidations",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "json"
+			}`)); err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSON([]byte(`{
+				"hidden": false,
+				"id": "autodate2990389176",
+				"name": "created",
+				"onCreate": true,
+				"onUpdate": false,
+				"presentable": false,
+				"system": false,
+				"type": "autodate"
+			}`)); err != nil {
+				return err
+			}
+
+			// add field
+			if err := collection.Fields.AddMarshaledJSON([]byte(`{
+				"hidden": false,
+				"id": "autodate3332085495",
+				"name": "updated",
+				"onCreate": true,
+				"onUpdate": true,
+				"presentable": false,
+				"system": false,
+				"type": "autodate"
+			}`)); err != nil {
+				return err
+			}
+
+			collection.AddIndex("idx_acme_rate_limits_key", true, "`key`", "")
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' created", collection.Name)
+		}
+
+		tracer.Printf("done")
+		return nil
+	}, func(app core.App) error {
+		return nil
+	})
+}
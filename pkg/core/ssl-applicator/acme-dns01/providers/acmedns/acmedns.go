@@ -0,0 +1,54 @@
+package acmedns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/certimate-go/certimate/pkg/core"
+	"github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/acmedns/internal"
+	acmednssdk "github.com/certimate-go/certimate/pkg/sdk3rd/acmedns"
+)
+
+type ChallengeProviderConfig struct {
+	ServerUrl string `json:"serverUrl"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+	Subdomain string `json:"subdomain,omitempty"`
+	// 各域名对应的账户凭据，JSON 格式，与 acme-dns-client 及 lego 的凭据存储文件格式一致。
+	// 未在凭据中找到域名时，使用 [Username]、[Password] 和 [Subdomain] 指定的账户。
+	Credentials           string `json:"credentials,omitempty"`
+	DnsPropagationTimeout int32  `json:"dnsPropagationTimeout,omitempty"`
+}
+
+func NewChallengeProvider(config *ChallengeProviderConfig) (core.ACMEChallenger, error) {
+	if config == nil {
+		return nil, errors.New("the configuration of the acme challenge provider is nil")
+	}
+
+	providerConfig := internal.NewDefaultConfig()
+	providerConfig.ServerURL = config.ServerUrl
+	if config.Username != "" {
+		providerConfig.DefaultAccount = &acmednssdk.Account{
+			Username:  config.Username,
+			Password:  config.Password,
+			Subdomain: config.Subdomain,
+		}
+	}
+	if config.Credentials != "" {
+		if err := json.Unmarshal([]byte(config.Credentials), &providerConfig.Accounts); err != nil {
+			return nil, fmt.Errorf("failed to parse acme-dns credentials: %w", err)
+		}
+	}
+	if config.DnsPropagationTimeout != 0 {
+		providerConfig.PropagationTimeout = time.Duration(config.DnsPropagationTimeout) * time.Second
+	}
+
+	provider, err := internal.NewDNSProviderConfig(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/platform/config/env"
+
+	acmednssdk "github.com/certimate-go/certimate/pkg/sdk3rd/acmedns"
+)
+
+const (
+	envNamespace = "ACME_DNS_"
+
+	EnvServerURL = envNamespace + "API_BASE"
+	EnvUsername  = envNamespace + "USERNAME"
+	EnvPassword  = envNamespace + "PASSWORD"
+	EnvSubdomain = envNamespace + "SUBDOMAIN"
+
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+)
+
+var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
+
+type Config struct {
+	ServerURL string
+	// 各域名对应的 acme-dns 账户，键为不含通配符前缀的域名。
+	Accounts map[string]acmednssdk.Account
+	// 未在 [Config.Accounts] 中找到域名时使用的账户。
+	DefaultAccount *acmednssdk.Account
+
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+	HTTPTimeout        time.Duration
+}
+
+type DNSProvider struct {
+	client *acmednssdk.Client
+	config *Config
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Accounts:           make(map[string]acmednssdk.Account),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		HTTPTimeout:        env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+	}
+}
+
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get(EnvServerURL, EnvUsername, EnvPassword, EnvSubdomain)
+	if err != nil {
+		return nil, fmt.Errorf("acmedns: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.ServerURL = values[EnvServerURL]
+	config.DefaultAccount = &acmednssdk.Account{
+		Username:  values[EnvUsername],
+		Password:  values[EnvPassword],
+		Subdomain: values[EnvSubdomain],
+	}
+
+	return NewDNSProviderConfig(config)
+}
+
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("acmedns: the configuration of the DNS provider is nil")
+	}
+
+	client, err := acmednssdk.NewClient(config.ServerURL)
+	if err != nil {
+		return nil, fmt.Errorf("acmedns: %w", err)
+	} else {
+		client.SetTimeout(config.HTTPTimeout)
+	}
+
+	return &DNSProvider{
+		client: client,
+		config: config,
+	}, nil
+}
+
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	account := d.findAccount(domain)
+	if account == nil {
+		return fmt.Errorf("acmedns: no account found for domain %q, please register one and create a CNAME record '_acme-challenge.%s' pointing to its full domain", domain, dns01.UnFqdn(strings.TrimPrefix(domain, "*.")))
+	}
+
+	request := &acmednssdk.UpdateRequest{
+		Username:  account.Username,
+		Password:  account.Password,
+		Subdomain: account.Subdomain,
+		Txt:       info.Value,
+	}
+	if _, err := d.client.Update(request); err != nil {
+		return fmt.Errorf("acmedns: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	// acme-dns 只保留每个子域名最近的两条 TXT 记录，且不提供删除接口，因此无需清理
+	return nil
+}
+
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+func (d *DNSProvider) findAccount(domain string) *acmednssdk.Account {
+	domain = strings.ToLower(dns01.UnFqdn(strings.TrimPrefix(domain, "*.")))
+	for key, account := range d.config.Accounts {
+		if strings.ToLower(dns01.UnFqdn(strings.TrimPrefix(key, "*."))) == domain {
+			return &account
+		}
+	}
+
+	if d.config.DefaultAccount != nil && d.config.DefaultAccount.Username != "" {
+		return d.config.DefaultAccount
+	}
+
+	return nil
+}
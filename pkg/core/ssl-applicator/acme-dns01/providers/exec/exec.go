@@ -0,0 +1,54 @@
+package exec
+
+import (
+	"errors"
+	"time"
+
+	"github.com/certimate-go/certimate/pkg/core"
+	"github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/exec/internal"
+	xshell "github.com/certimate-go/certimate/pkg/utils/shell"
+)
+
+type ChallengeProviderConfig struct {
+	// Shell 执行环境，仅对 [Script] 生效。
+	// 零值时根据操作系统决定。
+	ShellEnv string `json:"shellEnv,omitempty"`
+	// 程序路径。
+	// 不经过 Shell 直接执行，执行时传入参数 `{action} {fqdn} {value}`，其中 action 为 `present` 或 `cleanup`。
+	Command string `json:"command,omitempty"`
+	// 脚本内容。
+	// 非零值时忽略 [Command]；参数可通过位置参数（仅 sh）或环境变量获取。
+	Script string `json:"script,omitempty"`
+	// 额外的环境变量。
+	// 此外，命令执行时还会设置环境变量 `CERTIMATE_DNS01_ACTION`、`CERTIMATE_DNS01_DOMAIN`、`CERTIMATE_DNS01_FQDN`、`CERTIMATE_DNS01_VALUE` 和 `CERTIMATE_DNS01_TTL`。
+	Environment           map[string]string `json:"environment,omitempty"`
+	DnsPropagationTimeout int32             `json:"dnsPropagationTimeout,omitempty"`
+	DnsTTL                int32             `json:"dnsTTL,omitempty"`
+}
+
+func NewChallengeProvider(config *ChallengeProviderConfig) (core.ACMEChallenger, error) {
+	if config == nil {
+		return nil, errors.New("the configuration of the acme challenge provider is nil")
+	}
+
+	providerConfig := internal.NewDefaultConfig()
+	providerConfig.ShellEnv = xshell.ShellEnvType(config.ShellEnv)
+	providerConfig.Command = config.Command
+	providerConfig.Script = config.Script
+	for key, value := range config.Environment {
+		providerConfig.Environment[key] = value
+	}
+	if config.DnsPropagationTimeout != 0 {
+		providerConfig.PropagationTimeout = time.Duration(config.DnsPropagationTimeout) * time.Second
+	}
+	if config.DnsTTL != 0 {
+		providerConfig.TTL = int(config.DnsTTL)
+	}
+
+	provider, err := internal.NewDNSProviderConfig(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
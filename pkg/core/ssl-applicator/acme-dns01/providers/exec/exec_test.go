@@ -0,0 +1,114 @@
+package exec_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+
+	provider "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/exec"
+)
+
+const testScript = `printf '%s|%s|%s|%s|%s|%s|%s|%s|%s\n' "$1" "$2" "$3" \
+	"$CERTIMATE_DNS01_ACTION" "$CERTIMATE_DNS01_DOMAIN" "$CERTIMATE_DNS01_FQDN" "$CERTIMATE_DNS01_VALUE" "$CERTIMATE_DNS01_TTL" "$EXTRA" >> "$OUTPUT"
+`
+
+func TestPresentAndCleanUp(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test scripts require sh")
+	}
+
+	// 测试域名不存在于公共 DNS 中，不跟随 CNAME
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	const domain = "example.test"
+	const keyAuth = "token.thumbprint"
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	// 路径中包含空格及 Shell 特殊字符
+	dir := filepath.Join(t.TempDir(), "dns hooks & $HOME")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	commandPath := filepath.Join(dir, "hook.sh")
+	if err := os.WriteFile(commandPath, []byte("#!/bin/sh\n"+testScript), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config provider.ChallengeProviderConfig
+	}{
+		{
+			name:   "command",
+			config: provider.ChallengeProviderConfig{Command: commandPath},
+		},
+		{
+			name:   "script",
+			config: provider.ChallengeProviderConfig{Script: testScript},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "output")
+
+			config := tt.config
+			config.DnsTTL = 120
+			config.Environment = map[string]string{"EXTRA": "extra value", "OUTPUT": output}
+			challenger, err := provider.NewChallengeProvider(&config)
+			if err != nil {
+				t.Fatalf("err: %+v", err)
+			}
+
+			if err := challenger.Present(domain, "token", keyAuth); err != nil {
+				t.Fatalf("Present() err: %+v", err)
+			}
+			if err := challenger.CleanUp(domain, "token", keyAuth); err != nil {
+				t.Fatalf("CleanUp() err: %+v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := strings.Split(strings.TrimSpace(string(data)), "\n")
+			want := []string{
+				strings.Join([]string{"present", info.EffectiveFQDN, info.Value, "present", domain, info.EffectiveFQDN, info.Value, "120", "extra value"}, "|"),
+				strings.Join([]string{"cleanup", info.EffectiveFQDN, info.Value, "cleanup", domain, info.EffectiveFQDN, info.Value, "120", "extra value"}, "|"),
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d invocation(s), want %d: %q", len(got), len(want), got)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("invocation #%d = '%s', want '%s'", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCommandFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test scripts require sh")
+	}
+
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	challenger, err := provider.NewChallengeProvider(&provider.ChallengeProviderConfig{
+		Script: "echo 'zone not found' >&2; exit 1",
+	})
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+
+	err = challenger.Present("example.test", "token", "token.thumbprint")
+	if err == nil || !strings.Contains(err.Error(), "zone not found") {
+		t.Errorf("Present() err = %v, want the stderr of the command", err)
+	}
+}
@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/platform/config/env"
+
+	xshell "github.com/certimate-go/certimate/pkg/utils/shell"
+)
+
+const (
+	envNamespace = "EXEC_"
+
+	EnvShellEnv = envNamespace + "SHELL_ENV"
+	EnvPath     = envNamespace + "PATH"
+
+	EnvTTL                = envNamespace + "TTL"
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+	EnvCommandTimeout     = envNamespace + "COMMAND_TIMEOUT"
+)
+
+// 传递给命令的环境变量名。
+const (
+	CmdEnvAction = "CERTIMATE_DNS01_ACTION"
+	CmdEnvDomain = "CERTIMATE_DNS01_DOMAIN"
+	CmdEnvFQDN   = "CERTIMATE_DNS01_FQDN"
+	CmdEnvValue  = "CERTIMATE_DNS01_VALUE"
+	CmdEnvTTL    = "CERTIMATE_DNS01_TTL"
+)
+
+const (
+	ActionPresent = "present"
+	ActionCleanUp = "cleanup"
+)
+
+var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
+
+type Config struct {
+	// Shell 执行环境，仅对 [Config.Script] 生效。
+	ShellEnv xshell.ShellEnvType
+	// 程序路径，不经过 Shell 直接执行，并传入参数 `{action} {fqdn} {value}`。
+	Command string
+	// 脚本内容，在 Shell 中执行，可通过位置参数或环境变量获取参数；非零值时忽略 [Config.Command]。
+	Script string
+	// 额外的环境变量。
+	Environment map[string]string
+
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+	TTL                int
+	CommandTimeout     time.Duration
+}
+
+type DNSProvider struct {
+	config *Config
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Environment:        make(map[string]string),
+		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		CommandTimeout:     env.GetOrDefaultSecond(EnvCommandTimeout, 2*time.Minute),
+	}
+}
+
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get(EnvPath)
+	if err != nil {
+		return nil, fmt.Errorf("exec: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.ShellEnv = xshell.ShellEnvType(env.GetOrDefaultString(EnvShellEnv, ""))
+	config.Command = values[EnvPath]
+
+	return NewDNSProviderConfig(config)
+}
+
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("exec: the configuration of the DNS provider is nil")
+	}
+
+	if config.Command == "" && config.Script == "" {
+		return nil, errors.New("exec: command or script is required")
+	}
+
+	return &DNSProvider{config: config}, nil
+}
+
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	if err := d.run(ActionPresent, domain, info); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	if err := d.run(ActionCleanUp, domain, info); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+func (d *DNSProvider) run(action string, domain string, info dns01.ChallengeInfo) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.CommandTimeout)
+	defer cancel()
+
+	args := []string{action, info.EffectiveFQDN, info.Value}
+
+	var cmd *exec.Cmd
+	if d.config.Script != "" {
+		c, err := xshell.NewCommand(ctx, d.config.ShellEnv, d.config.Script, args...)
+		if err != nil {
+			return err
+		}
+		cmd = c
+	} else {
+		// 与 lego 的 exec 提供商一致，直接执行程序，以免路径中的空格或特殊字符被 Shell 解释
+		cmd = exec.CommandContext(ctx, d.config.Command, args...)
+	}
+
+	cmd.Env = os.Environ()
+	for key, value := range d.config.Environment {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	cmd.Env = append(cmd.Env,
+		fmt.Sprintf("%s=%s", CmdEnvAction, action),
+		fmt.Sprintf("%s=%s", CmdEnvDomain, domain),
+		fmt.Sprintf("%s=%s", CmdEnvFQDN, info.EffectiveFQDN),
+		fmt.Sprintf("%s=%s", CmdEnvValue, info.Value),
+		fmt.Sprintf("%s=%s", CmdEnvTTL, strconv.Itoa(d.config.TTL)),
+	)
+
+	stdout, stderr, err := xshell.RunCommand(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to run %s command (stdout: %s, stderr: %s): %w", action, stdout, stderr, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package rfc2136
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-acme/lego/v4/providers/dns/rfc2136"
+
+	"github.com/certimate-go/certimate/pkg/core"
+)
+
+type ChallengeProviderConfig struct {
+	Nameserver            string `json:"nameserver"`
+	TsigKey               string `json:"tsigKey,omitempty"`
+	TsigAlgorithm         string `json:"tsigAlgorithm,omitempty"`
+	TsigSecret            string `json:"tsigSecret,omitempty"`
+	DnsPropagationTimeout int32  `json:"dnsPropagationTimeout,omitempty"`
+	DnsTTL                int32  `json:"dnsTTL,omitempty"`
+}
+
+func NewChallengeProvider(config *ChallengeProviderConfig) (core.ACMEChallenger, error) {
+	if config == nil {
+		return nil, errors.New("the configuration of the acme challenge provider is nil")
+	}
+
+	providerConfig := rfc2136.NewDefaultConfig()
+	providerConfig.Nameserver = config.Nameserver
+	providerConfig.TSIGKey = config.TsigKey
+	providerConfig.TSIGSecret = config.TsigSecret
+	if config.TsigAlgorithm != "" {
+		providerConfig.TSIGAlgorithm = config.TsigAlgorithm
+	}
+	if config.DnsPropagationTimeout != 0 {
+		providerConfig.PropagationTimeout = time.Duration(config.DnsPropagationTimeout) * time.Second
+	}
+	if config.DnsTTL != 0 {
+		providerConfig.TTL = int(config.DnsTTL)
+	}
+
+	provider, err := rfc2136.NewDNSProviderConfig(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,259 @@
+package rfc2136_test
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/miekg/dns"
+
+	provider "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-dns01/providers/rfc2136"
+)
+
+var (
+	fNameserver    string
+	fTsigKey       string
+	fTsigAlgorithm string
+	fTsigSecret    string
+	fDomain        string
+)
+
+func init() {
+	argsPrefix := "CERTIMATE_SSLAPPLICATOR_DNS01_RFC2136_"
+
+	flag.StringVar(&fNameserver, argsPrefix+"NAMESERVER", "", "")
+	flag.StringVar(&fTsigKey, argsPrefix+"TSIGKEY", "certimate.", "")
+	flag.StringVar(&fTsigAlgorithm, argsPrefix+"TSIGALGORITHM", dns.HmacSHA256, "")
+	flag.StringVar(&fTsigSecret, argsPrefix+"TSIGSECRET", "c2VjcmV0LWtleS1mb3ItY2VydGltYXRlLXRlc3Q=", "")
+	flag.StringVar(&fDomain, argsPrefix+"DOMAIN", "example.test", "")
+}
+
+/*
+Shell command to run this test (e.g. against a local BIND whose zone allows
+updates signed with the given TSIG key). If the nameserver is omitted, an
+in-process DNS server is started instead:
+
+	go test -v ./rfc2136_test.go -args \
+	--CERTIMATE_SSLAPPLICATOR_DNS01_RFC2136_NAMESERVER="127.0.0.1:53" \
+	--CERTIMATE_SSLAPPLICATOR_DNS01_RFC2136_TSIGKEY="your-tsig-key-name" \
+	--CERTIMATE_SSLAPPLICATOR_DNS01_RFC2136_TSIGALGORITHM="hmac-sha256." \
+	--CERTIMATE_SSLAPPLICATOR_DNS01_RFC2136_TSIGSECRET="your-tsig-secret" \
+	--CERTIMATE_SSLAPPLICATOR_DNS01_RFC2136_DOMAIN="example.com"
+*/
+func TestPresentAndCleanUp(t *testing.T) {
+	flag.Parse()
+
+	// 测试域名可能不存在于公共 DNS 中，不跟随 CNAME
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	var server *testServer
+	nameserver := fNameserver
+	if nameserver == "" {
+		var err error
+		server, err = startTestServer(dns.Fqdn(fDomain), dns.Fqdn(fTsigKey), fTsigSecret)
+		if err != nil {
+			t.Fatalf("err: %+v", err)
+		}
+		defer server.Shutdown()
+
+		nameserver = server.Addr
+	}
+
+	t.Run("PresentAndCleanUp", func(t *testing.T) {
+		t.Log(strings.Join([]string{
+			"args:",
+			fmt.Sprintf("NAMESERVER: %v", nameserver),
+			fmt.Sprintf("TSIGKEY: %v", fTsigKey),
+			fmt.Sprintf("TSIGALGORITHM: %v", fTsigAlgorithm),
+			fmt.Sprintf("DOMAIN: %v", fDomain),
+		}, "\n"))
+
+		challenger, err := provider.NewChallengeProvider(&provider.ChallengeProviderConfig{
+			Nameserver:    nameserver,
+			TsigKey:       fTsigKey,
+			TsigAlgorithm: fTsigAlgorithm,
+			TsigSecret:    fTsigSecret,
+		})
+		if err != nil {
+			t.Errorf("err: %+v", err)
+			return
+		}
+
+		keyAuth := "certimate-test-key-authorization"
+		info := dns01.GetChallengeInfo(fDomain, keyAuth)
+
+		if err := challenger.Present(fDomain, "", keyAuth); err != nil {
+			t.Errorf("err: %+v", err)
+			return
+		}
+		if server != nil && !server.HasTXT(info.EffectiveFQDN, info.Value) {
+			t.Errorf("err: txt record '%s' not found after present", info.EffectiveFQDN)
+			return
+		}
+
+		if err := challenger.CleanUp(fDomain, "", keyAuth); err != nil {
+			t.Errorf("err: %+v", err)
+			return
+		}
+		if server != nil && server.HasTXT(info.EffectiveFQDN, info.Value) {
+			t.Errorf("err: txt record '%s' still exists after clean up", info.EffectiveFQDN)
+			return
+		}
+
+		t.Logf("ok: %s", info.EffectiveFQDN)
+	})
+
+	if server != nil {
+		t.Run("RejectInvalidTSIG", func(t *testing.T) {
+			challenger, err := provider.NewChallengeProvider(&provider.ChallengeProviderConfig{
+				Nameserver:    nameserver,
+				TsigKey:       fTsigKey,
+				TsigAlgorithm: fTsigAlgorithm,
+				TsigSecret:    "d3Jvbmctc2VjcmV0",
+			})
+			if err != nil {
+				t.Errorf("err: %+v", err)
+				return
+			}
+
+			if err := challenger.Present(fDomain, "", "certimate-test-key-authorization"); err == nil {
+				t.Errorf("err: expected update with invalid tsig to be rejected")
+				return
+			}
+		})
+	}
+}
+
+// 仅支持单个区域 SOA 查询与 TXT 记录动态更新的进程内 DNS 服务器。
+type testServer struct {
+	Addr string
+
+	zone    string
+	server  *dns.Server
+	mtx     sync.Mutex
+	records map[string][]string
+}
+
+func startTestServer(zone, tsigKey, tsigSecret string) (*testServer, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &testServer{
+		Addr:    conn.LocalAddr().String(),
+		zone:    zone,
+		records: make(map[string][]string),
+	}
+
+	started := make(chan struct{})
+	ts.server = &dns.Server{
+		PacketConn:        conn,
+		Handler:           dns.HandlerFunc(ts.serveDNS),
+		TsigSecret:        map[string]string{tsigKey: tsigSecret},
+		NotifyStartedFunc: func() { close(started) },
+		MsgAcceptFunc: func(dh dns.Header) dns.MsgAcceptAction {
+			// 默认的 MsgAcceptFunc 会拒绝动态更新请求
+			if opcode := int(dh.Bits>>11) & 0xF; opcode == dns.OpcodeUpdate {
+				return dns.MsgAccept
+			}
+			return dns.DefaultMsgAcceptFunc(dh)
+		},
+	}
+	go ts.server.ActivateAndServe()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		return nil, fmt.Errorf("failed to start dns server")
+	}
+
+	return ts, nil
+}
+
+func (ts *testServer) Shutdown() {
+	ts.server.Shutdown()
+}
+
+func (ts *testServer) HasTXT(fqdn, value string) bool {
+	ts.mtx.Lock()
+	defer ts.mtx.Unlock()
+
+	for _, v := range ts.records[strings.ToLower(fqdn)] {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts *testServer) serveDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+
+	switch r.Opcode {
+	case dns.OpcodeQuery:
+		q := r.Question[0]
+		if q.Qtype == dns.TypeSOA && strings.EqualFold(q.Name, ts.zone) {
+			m.Authoritative = true
+			m.Answer = append(m.Answer, &dns.SOA{
+				Hdr:     dns.RR_Header{Name: ts.zone, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 60},
+				Ns:      "ns." + ts.zone,
+				Mbox:    "hostmaster." + ts.zone,
+				Serial:  1,
+				Refresh: 3600,
+				Retry:   600,
+				Expire:  86400,
+				Minttl:  60,
+			})
+		} else if !dns.IsSubDomain(ts.zone, q.Name) {
+			m.Rcode = dns.RcodeRefused
+		} else {
+			m.Rcode = dns.RcodeNameError
+		}
+
+	case dns.OpcodeUpdate:
+		if r.IsTsig() == nil || w.TsigStatus() != nil {
+			m.Rcode = dns.RcodeNotAuth
+			break
+		}
+
+		ts.mtx.Lock()
+		for _, rr := range r.Ns {
+			name := strings.ToLower(rr.Header().Name)
+			switch rr.Header().Class {
+			case dns.ClassANY:
+				delete(ts.records, name)
+			case dns.ClassNONE:
+				if txt, ok := rr.(*dns.TXT); ok {
+					value := strings.Join(txt.Txt, "")
+					values := make([]string, 0)
+					for _, v := range ts.records[name] {
+						if v != value {
+							values = append(values, v)
+						}
+					}
+					ts.records[name] = values
+				}
+			case dns.ClassINET:
+				if txt, ok := rr.(*dns.TXT); ok {
+					ts.records[name] = append(ts.records[name], strings.Join(txt.Txt, ""))
+				}
+			}
+		}
+		ts.mtx.Unlock()
+
+	default:
+		m.Rcode = dns.RcodeNotImplemented
+	}
+
+	if tsig := r.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+	w.WriteMsg(m)
+}
@@ -0,0 +1,30 @@
+package builtin
+
+import (
+	"github.com/certimate-go/certimate/pkg/core"
+	"github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-http01/providers/builtin/internal"
+)
+
+type ChallengeProviderConfig struct{}
+
+func NewChallengeProvider(config *ChallengeProviderConfig) (core.ACMEChallenger, error) {
+	provider, err := internal.NewHTTPProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// 获取指定质询令牌对应的密钥授权内容。
+// 用于由 Certimate 自身的 HTTP 服务响应 "/.well-known/acme-challenge/{token}" 请求。
+//
+// 入参:
+//   - token: 质询令牌。
+//
+// 出参:
+//   - keyAuth: 密钥授权内容。
+//   - ok: 是否存在。
+func GetKeyAuthorization(token string) (_keyAuth string, _ok bool) {
+	return internal.GetKeyAuthorization(token)
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/go-acme/lego/v4/challenge"
+)
+
+var _ challenge.Provider = (*HTTPProvider)(nil)
+
+// 进程内共享的质询令牌存储。
+// 同一令牌可能被多个并发的申请流程引用，因此使用引用计数。
+var store = struct {
+	sync.RWMutex
+	tokens map[string]*storeEntry
+}{
+	tokens: make(map[string]*storeEntry),
+}
+
+type storeEntry struct {
+	keyAuth string
+	refs    int
+}
+
+type HTTPProvider struct{}
+
+func NewHTTPProvider() (*HTTPProvider, error) {
+	return &HTTPProvider{}, nil
+}
+
+func (p *HTTPProvider) Present(domain, token, keyAuth string) error {
+	if token == "" {
+		return errors.New("builtin: token is empty")
+	}
+
+	store.Lock()
+	defer store.Unlock()
+
+	if entry, ok := store.tokens[token]; ok {
+		entry.keyAuth = keyAuth
+		entry.refs++
+	} else {
+		store.tokens[token] = &storeEntry{keyAuth: keyAuth, refs: 1}
+	}
+
+	return nil
+}
+
+func (p *HTTPProvider) CleanUp(domain, token, keyAuth string) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if entry, ok := store.tokens[token]; ok {
+		entry.refs--
+		if entry.refs <= 0 {
+			delete(store.tokens, token)
+		}
+	}
+
+	return nil
+}
+
+func GetKeyAuthorization(token string) (string, bool) {
+	store.RLock()
+	defer store.RUnlock()
+
+	if entry, ok := store.tokens[token]; ok {
+		return entry.keyAuth, true
+	}
+
+	return "", false
+}
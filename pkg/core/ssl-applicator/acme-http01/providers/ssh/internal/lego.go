@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/http01"
+
+	xssh "github.com/certimate-go/certimate/pkg/utils/ssh"
+)
+
+var _ challenge.ProviderTimeout = (*HTTPProvider)(nil)
+
+type Config struct {
+	Server      xssh.ServerConfig
+	JumpServers []xssh.ServerConfig
+	UseSCP      bool
+	WebRootPath string
+
+	ConnectTimeout time.Duration
+}
+
+type HTTPProvider struct {
+	config *Config
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		ConnectTimeout: 30 * time.Second,
+	}
+}
+
+func NewHTTPProviderConfig(config *Config) (*HTTPProvider, error) {
+	if config == nil {
+		return nil, errors.New("ssh: the configuration of the HTTP provider is nil")
+	}
+	if config.WebRootPath == "" {
+		return nil, errors.New("ssh: webroot path is required")
+	}
+	if config.ConnectTimeout == 0 {
+		config.ConnectTimeout = NewDefaultConfig().ConnectTimeout
+	}
+
+	return &HTTPProvider{config: config}, nil
+}
+
+func (p *HTTPProvider) Present(domain, token, keyAuth string) error {
+	client, err := p.dial()
+	if err != nil {
+		return fmt.Errorf("ssh: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.WriteFileString(p.config.UseSCP, p.getChallengeFilePath(token), keyAuth); err != nil {
+		return fmt.Errorf("ssh: could not write file in webroot for HTTP challenge: %w", err)
+	}
+
+	return nil
+}
+
+func (p *HTTPProvider) CleanUp(domain, token, keyAuth string) error {
+	client, err := p.dial()
+	if err != nil {
+		return fmt.Errorf("ssh: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.RemoveFile(p.config.UseSCP, p.getChallengeFilePath(token)); err != nil {
+		return fmt.Errorf("ssh: could not remove file in webroot after HTTP challenge: %w", err)
+	}
+
+	return nil
+}
+
+func (p *HTTPProvider) Timeout() (timeout, interval time.Duration) {
+	return p.config.ConnectTimeout * 2, time.Second
+}
+
+func (p *HTTPProvider) dial() (*xssh.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.ConnectTimeout)
+	defer cancel()
+
+	return xssh.Dial(ctx, p.config.Server, p.config.JumpServers)
+}
+
+func (p *HTTPProvider) getChallengeFilePath(token string) string {
+	// 远程服务器通常为类 Unix 系统，因此使用正斜杠拼接路径
+	return path.Join(p.config.WebRootPath, http01.ChallengePath(token))
+}
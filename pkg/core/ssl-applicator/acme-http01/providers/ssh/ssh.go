@@ -0,0 +1,97 @@
+package ssh
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/certimate-go/certimate/pkg/core"
+	"github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-http01/providers/ssh/internal"
+	xssh "github.com/certimate-go/certimate/pkg/utils/ssh"
+)
+
+type JumpServerConfig struct {
+	SshHost               string `json:"sshHost,omitempty"`
+	SshPort               int32  `json:"sshPort,omitempty"`
+	SshAuthMethod         string `json:"sshAuthMethod,omitempty"`
+	SshUsername           string `json:"sshUsername,omitempty"`
+	SshPassword           string `json:"sshPassword,omitempty"`
+	SshKey                string `json:"sshKey,omitempty"`
+	SshKeyPassphrase      string `json:"sshKeyPassphrase,omitempty"`
+	SshHostKeyFingerprint string `json:"sshHostKeyFingerprint,omitempty"`
+}
+
+type ChallengeProviderConfig struct {
+	SshHost               string                                          `json:"sshHost,omitempty"`
+	SshPort               int32                                           `json:"sshPort,omitempty"`
+	SshAuthMethod         string                                          `json:"sshAuthMethod,omitempty"`
+	SshUsername           string                                          `json:"sshUsername,omitempty"`
+	SshPassword           string                                          `json:"sshPassword,omitempty"`
+	SshKey                string                                          `json:"sshKey,omitempty"`
+	SshKeyPassphrase      string                                          `json:"sshKeyPassphrase,omitempty"`
+	SshHostKeyVerifyMode  string                                          `json:"sshHostKeyVerifyMode,omitempty"`
+	SshHostKeyFingerprint string                                          `json:"sshHostKeyFingerprint,omitempty"`
+	SshKnownHosts         string                                          `json:"sshKnownHosts,omitempty"`
+	OnSshHostKeyTrusted   func(serverIndex int, fingerprint string) error `json:"-"`
+	JumpServers           []JumpServerConfig                              `json:"jumpServers,omitempty"`
+	UseSCP                bool                                            `json:"useSCP,omitempty"`
+	WebRootPath           string                                          `json:"webRootPath"`
+}
+
+func NewChallengeProvider(config *ChallengeProviderConfig) (core.ACMEChallenger, error) {
+	if config == nil {
+		return nil, errors.New("the configuration of the acme challenge provider is nil")
+	}
+
+	providerConfig := &internal.Config{
+		Server: xssh.ServerConfig{
+			Host:          config.SshHost,
+			Port:          config.SshPort,
+			AuthMethod:    config.SshAuthMethod,
+			Username:      config.SshUsername,
+			Password:      config.SshPassword,
+			Key:           config.SshKey,
+			KeyPassphrase: config.SshKeyPassphrase,
+
+			HostKeyVerifyMode:   config.SshHostKeyVerifyMode,
+			HostKeyFingerprints: strings.Split(config.SshHostKeyFingerprint, ";"),
+			KnownHosts:          config.SshKnownHosts,
+			OnHostKeyTrusted:    onHostKeyTrusted(config, 0),
+		},
+		JumpServers: make([]xssh.ServerConfig, len(config.JumpServers)),
+		UseSCP:      config.UseSCP,
+		WebRootPath: config.WebRootPath,
+	}
+	for i, jumpServer := range config.JumpServers {
+		providerConfig.JumpServers[i] = xssh.ServerConfig{
+			Host:          jumpServer.SshHost,
+			Port:          jumpServer.SshPort,
+			AuthMethod:    jumpServer.SshAuthMethod,
+			Username:      jumpServer.SshUsername,
+			Password:      jumpServer.SshPassword,
+			Key:           jumpServer.SshKey,
+			KeyPassphrase: jumpServer.SshKeyPassphrase,
+
+			HostKeyVerifyMode:   config.SshHostKeyVerifyMode,
+			HostKeyFingerprints: strings.Split(jumpServer.SshHostKeyFingerprint, ";"),
+			KnownHosts:          config.SshKnownHosts,
+			OnHostKeyTrusted:    onHostKeyTrusted(config, i+1),
+		}
+	}
+
+	provider, err := internal.NewHTTPProviderConfig(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+func onHostKeyTrusted(config *ChallengeProviderConfig, serverIndex int) func(fingerprint string) error {
+	if config.OnSshHostKeyTrusted == nil {
+		return nil
+	}
+
+	return func(fingerprint string) error {
+		return config.OnSshHostKeyTrusted(serverIndex, fingerprint)
+	}
+}
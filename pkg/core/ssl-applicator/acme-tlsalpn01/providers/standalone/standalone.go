@@ -0,0 +1,36 @@
+package standalone
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
+
+	"github.com/certimate-go/certimate/pkg/core"
+)
+
+type ChallengeProviderConfig struct {
+	// 临时 TLS 监听器绑定的地址。
+	// 零值时监听全部网络接口。
+	ListenAddress string `json:"listenAddress,omitempty"`
+	// 临时 TLS 监听器绑定的端口。
+	// 零值时默认值 443。
+	ListenPort int32 `json:"listenPort,omitempty"`
+}
+
+func NewChallengeProvider(config *ChallengeProviderConfig) (core.ACMEChallenger, error) {
+	if config == nil {
+		return nil, errors.New("the configuration of the acme challenge provider is nil")
+	}
+	if config.ListenPort < 0 || config.ListenPort > 65535 {
+		return nil, errors.New("the listen port of the acme challenge provider is invalid")
+	}
+
+	port := ""
+	if config.ListenPort != 0 {
+		port = strconv.Itoa(int(config.ListenPort))
+	}
+
+	provider := tlsalpn01.NewProviderServer(config.ListenAddress, port)
+	return provider, nil
+}
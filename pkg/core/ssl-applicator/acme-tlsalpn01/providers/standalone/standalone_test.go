@@ -0,0 +1,109 @@
+package standalone_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/registration"
+
+	provider "github.com/certimate-go/certimate/pkg/core/ssl-applicator/acme-tlsalpn01/providers/standalone"
+)
+
+var (
+	fDirectoryUrl  string
+	fDomain        string
+	fListenAddress string
+	fListenPort    int64
+)
+
+func init() {
+	argsPrefix := "CERTIMATE_SSLAPPLICATOR_TLSALPN01_STANDALONE_"
+
+	flag.StringVar(&fDirectoryUrl, argsPrefix+"DIRECTORYURL", "", "")
+	flag.StringVar(&fDomain, argsPrefix+"DOMAIN", "", "")
+	flag.StringVar(&fListenAddress, argsPrefix+"LISTENADDRESS", "", "")
+	flag.Int64Var(&fListenPort, argsPrefix+"LISTENPORT", 0, "")
+}
+
+type testUser struct {
+	registration *registration.Resource
+	key          crypto.PrivateKey
+}
+
+func (u *testUser) GetEmail() string                        { return "" }
+func (u *testUser) GetRegistration() *registration.Resource { return u.registration }
+func (u *testUser) GetPrivateKey() crypto.PrivateKey        { return u.key }
+
+/*
+Shell command to run this test (e.g. against a local Pebble server, whose
+`tlsPort` is set to 5001 and whose root certificate is trusted by setting
+the `LEGO_CA_CERTIFICATES` environment variable):
+
+	LEGO_CA_CERTIFICATES="/path/to/pebble.minica.pem" \
+	go test -v ./standalone_test.go -args \
+	--CERTIMATE_SSLAPPLICATOR_TLSALPN01_STANDALONE_DIRECTORYURL="https://localhost:14000/dir" \
+	--CERTIMATE_SSLAPPLICATOR_TLSALPN01_STANDALONE_DOMAIN="example.com" \
+	--CERTIMATE_SSLAPPLICATOR_TLSALPN01_STANDALONE_LISTENADDRESS="0.0.0.0" \
+	--CERTIMATE_SSLAPPLICATOR_TLSALPN01_STANDALONE_LISTENPORT=5001
+*/
+func TestObtain(t *testing.T) {
+	flag.Parse()
+
+	t.Run("Obtain", func(t *testing.T) {
+		t.Log(strings.Join([]string{
+			"args:",
+			fmt.Sprintf("DIRECTORYURL: %v", fDirectoryUrl),
+			fmt.Sprintf("DOMAIN: %v", fDomain),
+			fmt.Sprintf("LISTENADDRESS: %v", fListenAddress),
+			fmt.Sprintf("LISTENPORT: %v", fListenPort),
+		}, "\n"))
+
+		challenger, err := provider.NewChallengeProvider(&provider.ChallengeProviderConfig{
+			ListenAddress: fListenAddress,
+			ListenPort:    int32(fListenPort),
+		})
+		if err != nil {
+			t.Errorf("err: %+v", err)
+			return
+		}
+
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		user := &testUser{key: key}
+
+		config := lego.NewConfig(user)
+		config.CADirURL = fDirectoryUrl
+		client, err := lego.NewClient(config)
+		if err != nil {
+			t.Errorf("err: %+v", err)
+			return
+		}
+
+		if err := client.Challenge.SetTLSALPN01Provider(challenger); err != nil {
+			t.Errorf("err: %+v", err)
+			return
+		}
+
+		reg, err := client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+		if err != nil {
+			t.Errorf("err: %+v", err)
+			return
+		}
+		user.registration = reg
+
+		res, err := client.Certificate.Obtain(certificate.ObtainRequest{Domains: []string{fDomain}, Bundle: true})
+		if err != nil {
+			t.Errorf("err: %+v", err)
+			return
+		}
+
+		t.Logf("ok: %s", res.CertURL)
+	})
+}
@@ -1,5 +1,9 @@
 package local
 
+import (
+	xshell "github.com/certimate-go/certimate/pkg/utils/shell"
+)
+
 type OutputFormatType string
 
 const (
@@ -8,10 +12,10 @@ const (
 	OUTPUT_FORMAT_JKS = OutputFormatType("JKS")
 )
 
-type ShellEnvType string
+type ShellEnvType = xshell.ShellEnvType
 
 const (
-	SHELL_ENV_SH         = ShellEnvType("sh")
-	SHELL_ENV_CMD        = ShellEnvType("cmd")
-	SHELL_ENV_POWERSHELL = ShellEnvType("powershell")
+	SHELL_ENV_SH         = xshell.SHELL_ENV_SH
+	SHELL_ENV_CMD        = xshell.SHELL_ENV_CMD
+	SHELL_ENV_POWERSHELL = xshell.SHELL_ENV_POWERSHELL
 )
@@ -1,17 +1,19 @@
 package local
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
-	"os/exec"
-	"runtime"
+	"os"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/certimate-go/certimate/pkg/core"
 	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
 	xfile "github.com/certimate-go/certimate/pkg/utils/file"
+	xshell "github.com/certimate-go/certimate/pkg/utils/shell"
 )
 
 type SSLDeployerProviderConfig struct {
@@ -34,6 +36,20 @@ type SSLDeployerProviderConfig struct {
 	OutputIntermediaCertPath string `json:"outputIntermediaCertPath,omitempty"`
 	// 输出私钥文件路径。
 	OutputKeyPath string `json:"outputKeyPath,omitempty"`
+	// 输出文件权限，八进制格式（形如 "0600"）。
+	// 选填。
+	OutputFileMode string `json:"outputFileMode,omitempty"`
+	// 输出文件所有者用户名或用户 ID。
+	// 选填。
+	OutputFileOwner string `json:"outputFileOwner,omitempty"`
+	// 输出文件所属组名或组 ID。
+	// 选填。
+	OutputFileGroup string `json:"outputFileGroup,omitempty"`
+	// 是否以原子方式写入文件（先写入临时文件，再重命名为目标文件）。
+	UseAtomicWrite bool `json:"useAtomicWrite,omitempty"`
+	// 是否在写入前备份已有文件（备份文件路径为原路径追加 ".certimate-bak-<时间戳>" 后缀，不会覆盖已有的文件），并在写入失败或后置命令执行失败时回滚。
+	// 回滚后将重新执行后置命令；部署成功后将删除备份文件。
+	BackupAndRollback bool `json:"backupAndRollback,omitempty"`
 	// PFX 导出密码。
 	// 证书格式为 PFX 时必填。
 	PfxPassword string `json:"pfxPassword,omitempty"`
@@ -74,16 +90,27 @@ func (d *SSLDeployerProvider) SetLogger(logger *slog.Logger) {
 	}
 }
 
-func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privkeyPEM string) (*core.SSLDeployResult, error) {
+func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privkeyPEM string) (_res *core.SSLDeployResult, _err error) {
 	// 提取服务器证书和中间证书
 	serverCertPEM, intermediaCertPEM, err := xcert.ExtractCertificatesFromPEM(certPEM)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract certs: %w", err)
 	}
 
+	writer, err := newFileWriter(d.config)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _err != nil && len(writer.files) > 0 {
+			_err = d.rollback(ctx, writer, _err)
+			_res = &core.SSLDeployResult{ExtendedData: writer.extendedData()}
+		}
+	}()
+
 	// 执行前置命令
 	if d.config.PreCommand != "" {
-		stdout, stderr, err := execCommand(d.config.ShellEnv, d.config.PreCommand)
+		stdout, stderr, err := execCommand(ctx, d.config.ShellEnv, d.config.PreCommand)
 		d.logger.Debug("run pre-command", slog.String("stdout", stdout), slog.String("stderr", stderr))
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute pre-command (stdout: %s, stderr: %s): %w ", stdout, stderr, err)
@@ -93,26 +120,26 @@ func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privke
 	// 写入证书和私钥文件
 	switch d.config.OutputFormat {
 	case OUTPUT_FORMAT_PEM:
-		if err := xfile.WriteString(d.config.OutputCertPath, certPEM); err != nil {
+		if err := writer.Write(d.config.OutputCertPath, []byte(certPEM)); err != nil {
 			return nil, fmt.Errorf("failed to save certificate file: %w", err)
 		}
 		d.logger.Info("ssl certificate file saved", slog.String("path", d.config.OutputCertPath))
 
 		if d.config.OutputServerCertPath != "" {
-			if err := xfile.WriteString(d.config.OutputServerCertPath, serverCertPEM); err != nil {
+			if err := writer.Write(d.config.OutputServerCertPath, []byte(serverCertPEM)); err != nil {
 				return nil, fmt.Errorf("failed to save server certificate file: %w", err)
 			}
 			d.logger.Info("ssl server certificate file saved", slog.String("path", d.config.OutputServerCertPath))
 		}
 
 		if d.config.OutputIntermediaCertPath != "" {
-			if err := xfile.WriteString(d.config.OutputIntermediaCertPath, intermediaCertPEM); err != nil {
+			if err := writer.Write(d.config.OutputIntermediaCertPath, []byte(intermediaCertPEM)); err != nil {
 				return nil, fmt.Errorf("failed to save intermedia certificate file: %w", err)
 			}
 			d.logger.Info("ssl intermedia certificate file saved", slog.String("path", d.config.OutputIntermediaCertPath))
 		}
 
-		if err := xfile.WriteString(d.config.OutputKeyPath, privkeyPEM); err != nil {
+		if err := writer.Write(d.config.OutputKeyPath, []byte(privkeyPEM)); err != nil {
 			return nil, fmt.Errorf("failed to save private key file: %w", err)
 		}
 		d.logger.Info("ssl private key file saved", slog.String("path", d.config.OutputKeyPath))
@@ -124,7 +151,7 @@ func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privke
 		}
 		d.logger.Info("ssl certificate transformed to pfx")
 
-		if err := xfile.Write(d.config.OutputCertPath, pfxData); err != nil {
+		if err := writer.Write(d.config.OutputCertPath, pfxData); err != nil {
 			return nil, fmt.Errorf("failed to save certificate file: %w", err)
 		}
 		d.logger.Info("ssl certificate file saved", slog.String("path", d.config.OutputCertPath))
@@ -136,7 +163,7 @@ func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privke
 		}
 		d.logger.Info("ssl certificate transformed to jks")
 
-		if err := xfile.Write(d.config.OutputCertPath, jksData); err != nil {
+		if err := writer.Write(d.config.OutputCertPath, jksData); err != nil {
 			return nil, fmt.Errorf("failed to save certificate file: %w", err)
 		}
 		d.logger.Info("ssl certificate file saved", slog.String("path", d.config.OutputCertPath))
@@ -147,48 +174,194 @@ func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privke
 
 	// 执行后置命令
 	if d.config.PostCommand != "" {
-		stdout, stderr, err := execCommand(d.config.ShellEnv, d.config.PostCommand)
+		stdout, stderr, err := execCommand(ctx, d.config.ShellEnv, d.config.PostCommand)
 		d.logger.Debug("run post-command", slog.String("stdout", stdout), slog.String("stderr", stderr))
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute post-command (stdout: %s, stderr: %s): %w ", stdout, stderr, err)
 		}
 	}
 
-	return &core.SSLDeployResult{}, nil
+	// 部署成功后删除备份文件，以免私钥等文件的副本残留
+	if err := writer.RemoveBackups(); err != nil {
+		d.logger.Warn("failed to remove backup files", slog.Any("error", err))
+	}
+
+	return &core.SSLDeployResult{ExtendedData: writer.extendedData()}, nil
 }
 
-func execCommand(shellEnv ShellEnvType, command string) (string, string, error) {
-	var cmd *exec.Cmd
+func (d *SSLDeployerProvider) rollback(ctx context.Context, writer *fileWriter, cause error) error {
+	if !d.config.BackupAndRollback {
+		return cause
+	}
 
-	switch shellEnv {
-	case SHELL_ENV_SH:
-		cmd = exec.Command("sh", "-c", command)
+	if err := writer.Rollback(); err != nil {
+		d.logger.Warn("failed to roll back files", slog.Any("error", err))
+		return errors.Join(cause, fmt.Errorf("failed to roll back files: %w", err))
+	}
 
-	case SHELL_ENV_CMD:
-		cmd = exec.Command("cmd", "/C", command)
+	d.logger.Info("files rolled back")
+	cause = fmt.Errorf("%w (files have been rolled back)", cause)
 
-	case SHELL_ENV_POWERSHELL:
-		cmd = exec.Command("powershell", "-Command", command)
+	// 重新执行后置命令，以使服务重新加载已恢复的文件。
+	// 此时上下文可能已被取消（如节点超时或多主机部署快速失败），因此使用独立的超时时间。
+	if d.config.PostCommand != "" {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackPostCommandTimeout)
+		defer cancel()
 
-	case ShellEnvType(""):
-		if runtime.GOOS == "windows" {
-			cmd = exec.Command("cmd", "/C", command)
-		} else {
-			cmd = exec.Command("sh", "-c", command)
+		stdout, stderr, err := execCommand(ctx, d.config.ShellEnv, d.config.PostCommand)
+		d.logger.Debug("re-run post-command after rollback", slog.String("stdout", stdout), slog.String("stderr", stderr))
+		if err != nil {
+			d.logger.Warn("failed to re-run post-command after rollback", slog.Any("error", err))
+			return errors.Join(cause, fmt.Errorf("failed to re-run post-command after rollback (stdout: %s, stderr: %s): %w", stdout, stderr, err))
 		}
-
-	default:
-		return "", "", fmt.Errorf("unsupported shell env '%s'", shellEnv)
 	}
 
-	stdoutBuf := bytes.NewBuffer(nil)
-	cmd.Stdout = stdoutBuf
-	stderrBuf := bytes.NewBuffer(nil)
-	cmd.Stderr = stderrBuf
-	err := cmd.Run()
+	return cause
+}
+
+func execCommand(ctx context.Context, shellEnv ShellEnvType, command string) (string, string, error) {
+	cmd, err := xshell.NewCommand(ctx, shellEnv, command)
 	if err != nil {
-		return stdoutBuf.String(), stderrBuf.String(), fmt.Errorf("failed to execute command: %w", err)
+		return "", "", err
+	}
+
+	return xshell.RunCommand(cmd)
+}
+
+// 回滚后重新执行后置命令的超时时间。
+const rollbackPostCommandTimeout = 5 * time.Minute
+
+type writtenFile struct {
+	Path       string `json:"path"`
+	BackupPath string `json:"backupPath,omitempty"`
+	Created    bool   `json:"created"`
+}
+
+// 记录部署过程中写入的文件，以便在需要时回滚。
+type fileWriter struct {
+	config      *SSLDeployerProviderConfig
+	mode        os.FileMode
+	files       []*writtenFile
+	rolledBack  bool
+	rollbackErr error
+}
+
+func newFileWriter(config *SSLDeployerProviderConfig) (*fileWriter, error) {
+	writer := &fileWriter{
+		config: config,
+		files:  make([]*writtenFile, 0),
+	}
+
+	if config.OutputFileMode != "" {
+		mode, err := strconv.ParseUint(config.OutputFileMode, 8, 32)
+		if err != nil || mode > 0o777 {
+			return nil, fmt.Errorf("invalid output file mode '%s'", config.OutputFileMode)
+		}
+		writer.mode = os.FileMode(mode)
+	}
+
+	return writer, nil
+}
+
+func (w *fileWriter) Write(path string, data []byte) error {
+	// 同一文件被多次写入时，仅在首次写入前备份
+	if !slices.ContainsFunc(w.files, func(f *writtenFile) bool { return f.Path == path }) {
+		file := &writtenFile{Path: path}
+
+		if w.config.BackupAndRollback {
+			if _, err := os.Stat(path); err == nil {
+				file.BackupPath = newBackupPath(path)
+				if err := xfile.Copy(path, file.BackupPath); err != nil {
+					return fmt.Errorf("failed to backup file: %w", err)
+				}
+			} else if errors.Is(err, os.ErrNotExist) {
+				file.Created = true
+			} else {
+				return fmt.Errorf("failed to stat file: %w", err)
+			}
+		}
+
+		// 先记录再写入，以便写入失败时也能回滚
+		w.files = append(w.files, file)
+	}
+
+	if w.config.UseAtomicWrite {
+		if err := xfile.WriteAtomic(path, data); err != nil {
+			return err
+		}
+	} else {
+		if err := xfile.Write(path, data); err != nil {
+			return err
+		}
+	}
+
+	if w.mode != 0 {
+		if err := os.Chmod(path, w.mode); err != nil {
+			return fmt.Errorf("failed to change file mode: %w", err)
+		}
+	}
+
+	if err := xfile.Chown(path, w.config.OutputFileOwner, w.config.OutputFileGroup); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// 返回唯一的备份文件路径，以免覆盖或删除用户自行创建的备份文件（如 "*.bak"）。
+func newBackupPath(path string) string {
+	return fmt.Sprintf("%s.certimate-bak-%d", path, time.Now().UnixNano())
+}
+
+func (w *fileWriter) Rollback() error {
+	var errs []error
+
+	for i := len(w.files) - 1; i >= 0; i-- {
+		file := w.files[i]
+
+		if file.BackupPath != "" {
+			if err := os.Rename(file.BackupPath, file.Path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore file '%s': %w", file.Path, err))
+			}
+		} else if file.Created {
+			if err := os.Remove(file.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("failed to remove file '%s': %w", file.Path, err))
+			}
+		}
+	}
+
+	w.rolledBack = true
+	w.rollbackErr = errors.Join(errs...)
+	return w.rollbackErr
+}
+
+func (w *fileWriter) RemoveBackups() error {
+	var errs []error
+
+	for _, file := range w.files {
+		if file.BackupPath == "" {
+			continue
+		}
+
+		if err := os.Remove(file.BackupPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to remove backup file '%s': %w", file.BackupPath, err))
+			continue
+		}
+
+		file.BackupPath = ""
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w *fileWriter) extendedData() map[string]any {
+	data := map[string]any{
+		"files":      w.files,
+		"rolledBack": w.rolledBack && w.rollbackErr == nil,
+	}
+	if w.rollbackErr != nil {
+		data["rollbackError"] = w.rollbackErr.Error()
 	}
 
-	return stdoutBuf.String(), stderrBuf.String(), nil
+	return data
 }
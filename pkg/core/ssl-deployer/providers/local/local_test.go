@@ -2,11 +2,21 @@ package local_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"flag"
 	"fmt"
+	"math/big"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	provider "github.com/certimate-go/certimate/pkg/core/ssl-deployer/providers/local"
 )
@@ -196,3 +206,177 @@ func TestDeploy(t *testing.T) {
 		t.Logf("ok: %v", res)
 	})
 }
+
+func TestDeployRollback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	certPEM, privkeyPEM := newTestCertificate(t)
+
+	setup := func(t *testing.T) (string, string, string) {
+		dir := t.TempDir()
+		certPath := filepath.Join(dir, "cert.pem")
+		keyPath := filepath.Join(dir, "key.pem")
+		if err := os.WriteFile(certPath, []byte("old-cert"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(keyPath, []byte("old-key"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return dir, certPath, keyPath
+	}
+
+	assertFile := func(t *testing.T, path string, content string, mode os.FileMode) {
+		t.Helper()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("file '%s' content = %q, want %q", path, string(data), content)
+		}
+
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fileInfo.Mode().Perm() != mode {
+			t.Errorf("file '%s' mode = %04o, want %04o", path, fileInfo.Mode().Perm(), mode)
+		}
+
+		if backups, _ := filepath.Glob(path + ".certimate-bak-*"); len(backups) > 0 {
+			t.Errorf("backup files %q should not exist", backups)
+		}
+	}
+
+	t.Run("Rollback_On_PostCommand_Failure", func(t *testing.T) {
+		dir, certPath, keyPath := setup(t)
+		logPath := filepath.Join(dir, "post-command.log")
+
+		// 仅在证书文件已恢复时成功，以验证回滚后会重新执行后置命令
+		deployer, err := provider.NewSSLDeployerProvider(&provider.SSLDeployerProviderConfig{
+			ShellEnv:          provider.SHELL_ENV_SH,
+			PostCommand:       fmt.Sprintf(`cat '%s' >> '%s'; echo >> '%s'; [ "$(cat '%s')" = "old-cert" ]`, certPath, logPath, logPath, certPath),
+			OutputFormat:      provider.OUTPUT_FORMAT_PEM,
+			OutputCertPath:    certPath,
+			OutputKeyPath:     keyPath,
+			UseAtomicWrite:    true,
+			BackupAndRollback: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := deployer.Deploy(context.Background(), certPEM, privkeyPEM)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+		if !strings.Contains(err.Error(), "rolled back") {
+			t.Errorf("error = %v, want it to mention the rollback", err)
+		}
+		if res == nil || res.ExtendedData["rolledBack"] != true {
+			t.Errorf("result = %v, want rolledBack = true", res)
+		}
+
+		assertFile(t, certPath, "old-cert", 0o644)
+		assertFile(t, keyPath, "old-key", 0o600)
+
+		logData, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs := strings.Split(strings.TrimSpace(string(logData)), "\n")
+		if len(runs) < 2 || runs[len(runs)-1] != "old-cert" {
+			t.Errorf("post-command should be re-run on the restored files, got runs %q", runs)
+		}
+	})
+
+	t.Run("Rollback_Removes_Created_Files", func(t *testing.T) {
+		dir, certPath, keyPath := setup(t)
+		newKeyPath := filepath.Join(dir, "new-key.pem")
+
+		deployer, err := provider.NewSSLDeployerProvider(&provider.SSLDeployerProviderConfig{
+			ShellEnv:          provider.SHELL_ENV_SH,
+			PostCommand:       "exit 1",
+			OutputFormat:      provider.OUTPUT_FORMAT_PEM,
+			OutputCertPath:    certPath,
+			OutputKeyPath:     newKeyPath,
+			BackupAndRollback: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := deployer.Deploy(context.Background(), certPEM, privkeyPEM); err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+
+		assertFile(t, certPath, "old-cert", 0o644)
+		assertFile(t, keyPath, "old-key", 0o600)
+		if _, err := os.Stat(newKeyPath); !os.IsNotExist(err) {
+			t.Errorf("created file '%s' should be removed", newKeyPath)
+		}
+	})
+
+	t.Run("Backups_Removed_On_Success", func(t *testing.T) {
+		_, certPath, keyPath := setup(t)
+
+		// 用户自行创建的备份文件不应被覆盖或删除
+		if err := os.WriteFile(certPath+".bak", []byte("user-backup"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		deployer, err := provider.NewSSLDeployerProvider(&provider.SSLDeployerProviderConfig{
+			ShellEnv:          provider.SHELL_ENV_SH,
+			PostCommand:       "true",
+			OutputFormat:      provider.OUTPUT_FORMAT_PEM,
+			OutputCertPath:    certPath,
+			OutputKeyPath:     keyPath,
+			UseAtomicWrite:    true,
+			BackupAndRollback: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := deployer.Deploy(context.Background(), certPEM, privkeyPEM); err != nil {
+			t.Fatalf("err: %+v", err)
+		}
+
+		assertFile(t, certPath, certPEM, 0o644)
+		assertFile(t, keyPath, privkeyPEM, 0o600)
+		assertFile(t, certPath+".bak", "user-backup", 0o644)
+	})
+}
+
+func newTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	privkey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privkey.PublicKey, privkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privkeyDER, err := x509.MarshalECPrivateKey(privkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}))
+	privkeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privkeyDER}))
+	return certPEM, privkeyPEM
+}
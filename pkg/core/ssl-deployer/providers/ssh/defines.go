@@ -7,3 +7,14 @@ const (
 	OUTPUT_FORMAT_PFX = OutputFormatType("PFX")
 	OUTPUT_FORMAT_JKS = OutputFormatType("JKS")
 )
+
+type FailurePolicyType string
+
+const (
+	// 任一主机部署失败时，不再部署尚未开始的主机，并返回错误。
+	FAILURE_POLICY_FAILFAST = FailurePolicyType("failfast")
+	// 部署全部主机，仅当全部主机均部署失败时返回错误。
+	FAILURE_POLICY_BESTEFFORT = FailurePolicyType("besteffort")
+	// 部署全部主机，当部署成功的主机比例低于阈值时返回错误。
+	FAILURE_POLICY_RATIO = FailurePolicyType("ratio")
+)
@@ -1,23 +1,20 @@
 package ssh
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
-	"net"
 	"os"
-	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-
-	"github.com/pkg/sftp"
-	"github.com/povsister/scp"
-	"golang.org/x/crypto/ssh"
+	"sync"
+	"time"
 
 	"github.com/certimate-go/certimate/pkg/core"
 	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
+	xssh "github.com/certimate-go/certimate/pkg/utils/ssh"
 )
 
 type JumpServerConfig struct {
@@ -40,6 +37,20 @@ type JumpServerConfig struct {
 	SshKey string `json:"sshKey,omitempty"`
 	// SSH 登录私钥口令。
 	SshKeyPassphrase string `json:"sshKeyPassphrase,omitempty"`
+	// 已信任的 SSH 主机公钥指纹（SHA256 格式）。
+	// 多个值之间以半角分号分隔。
+	SshHostKeyFingerprint string `json:"sshHostKeyFingerprint,omitempty"`
+}
+
+type HostConfig struct {
+	// SSH 主机。
+	SshHost string `json:"sshHost"`
+	// SSH 端口。
+	// 零值时沿用 [SSLDeployerProviderConfig.SshPort]。
+	SshPort int32 `json:"sshPort,omitempty"`
+	// 已信任的 SSH 主机公钥指纹（SHA256 格式）。
+	// 多个值之间以半角分号分隔。
+	SshHostKeyFingerprint string `json:"sshHostKeyFingerprint,omitempty"`
 }
 
 type SSLDeployerProviderConfig struct {
@@ -62,8 +73,36 @@ type SSLDeployerProviderConfig struct {
 	SshKey string `json:"sshKey,omitempty"`
 	// SSH 登录私钥口令。
 	SshKeyPassphrase string `json:"sshKeyPassphrase,omitempty"`
+	// SSH 主机公钥校验模式。
+	// 可取值 "tofu"、"strict" 或 "none"，同时作用于目标服务器和跳板机。
+	// 零值时默认值 "tofu"。
+	SshHostKeyVerifyMode string `json:"sshHostKeyVerifyMode,omitempty"`
+	// 已信任的 SSH 主机公钥指纹（SHA256 格式）。
+	// 多个值之间以半角分号分隔。
+	SshHostKeyFingerprint string `json:"sshHostKeyFingerprint,omitempty"`
+	// 已信任的 SSH 主机公钥，known_hosts 文件格式。
+	// 同时作用于目标服务器和跳板机。
+	SshKnownHosts string `json:"sshKnownHosts,omitempty"`
+	// 首次连接时信任 SSH 主机公钥后的回调。
+	// 多主机部署时可能被并发调用。
+	// 未设置时，校验模式 "tofu" 将按 "strict" 处理。
+	// 入参 hostIndex 为 0 时表示主目标服务器，否则表示第 hostIndex 个额外目标主机；
+	// 入参 jumpServerIndex 为 0 时表示目标服务器本身，否则表示第 jumpServerIndex 个跳板机。
+	OnSshHostKeyTrusted func(hostIndex int, jumpServerIndex int, fingerprint string) error `json:"-"`
 	// 跳板机配置数组。
 	JumpServers []JumpServerConfig `json:"jumpServers,omitempty"`
+	// 额外的目标主机配置数组。
+	// 非空时，将并发部署到主目标服务器（如已指定 SshHost）和这些主机，其余连接及部署配置均共用。
+	Hosts []HostConfig `json:"hosts,omitempty"`
+	// 多主机部署时的最大并发数。
+	// 零值时默认值 5。
+	Parallelism int32 `json:"parallelism,omitempty"`
+	// 多主机部署时的失败策略。
+	// 零值时默认值 [FAILURE_POLICY_FAILFAST]。
+	FailurePolicy FailurePolicyType `json:"failurePolicy,omitempty"`
+	// 多主机部署时的最低成功比例（百分比，取值范围 1~100）。
+	// 失败策略为 [FAILURE_POLICY_RATIO] 时生效，零值时默认值 100。
+	MinSuccessPercent int32 `json:"minSuccessPercent,omitempty"`
 	// 是否回退使用 SCP。
 	UseSCP bool `json:"useSCP,omitempty"`
 	// 前置命令。
@@ -82,6 +121,20 @@ type SSLDeployerProviderConfig struct {
 	OutputIntermediaCertPath string `json:"outputIntermediaCertPath,omitempty"`
 	// 输出私钥文件路径。
 	OutputKeyPath string `json:"outputKeyPath,omitempty"`
+	// 输出文件权限，八进制格式（形如 "0600"）。
+	// 选填。
+	OutputFileMode string `json:"outputFileMode,omitempty"`
+	// 输出文件所有者用户名或用户 ID。
+	// 选填。
+	OutputFileOwner string `json:"outputFileOwner,omitempty"`
+	// 输出文件所属组名或组 ID。
+	// 选填。
+	OutputFileGroup string `json:"outputFileGroup,omitempty"`
+	// 是否以原子方式写入文件（先写入临时文件，再重命名为目标文件）。
+	UseAtomicWrite bool `json:"useAtomicWrite,omitempty"`
+	// 是否在写入前备份已有文件（备份文件路径为原路径追加 ".certimate-bak-<时间戳>" 后缀，不会覆盖已有的文件），并在写入失败或后置命令执行失败时回滚。
+	// 回滚后将重新执行后置命令；部署成功后将删除备份文件。
+	BackupAndRollback bool `json:"backupAndRollback,omitempty"`
 	// PFX 导出密码。
 	// 证书格式为 PFX 时必填。
 	PfxPassword string `json:"pfxPassword,omitempty"`
@@ -122,87 +175,70 @@ func (d *SSLDeployerProvider) SetLogger(logger *slog.Logger) {
 	}
 }
 
-func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privkeyPEM string) (*core.SSLDeployResult, error) {
+func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privkeyPEM string) (_res *core.SSLDeployResult, _err error) {
+	if len(d.config.Hosts) > 0 {
+		return d.deployToHosts(ctx, certPEM, privkeyPEM)
+	}
+
 	// 提取服务器证书和中间证书
 	serverCertPEM, intermediaCertPEM, err := xcert.ExtractCertificatesFromPEM(certPEM)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract certs: %w", err)
 	}
 
-	var targetConn net.Conn
-
-	// 连接到跳板机
-	if len(d.config.JumpServers) > 0 {
-		var jumpClient *ssh.Client
-		for i, jumpServerConf := range d.config.JumpServers {
-			d.logger.Info(fmt.Sprintf("connecting to jump server [%d]", i+1), slog.String("host", jumpServerConf.SshHost))
-
-			var jumpConn net.Conn
-			// 第一个连接是主机发起，后续通过跳板机发起
-			if jumpClient == nil {
-				jumpConn, err = net.Dial("tcp", net.JoinHostPort(jumpServerConf.SshHost, strconv.Itoa(int(jumpServerConf.SshPort))))
-			} else {
-				jumpConn, err = jumpClient.DialContext(ctx, "tcp", net.JoinHostPort(jumpServerConf.SshHost, strconv.Itoa(int(jumpServerConf.SshPort))))
-			}
-			if err != nil {
-				return nil, fmt.Errorf("failed to connect to jump server [%d]: %w", i+1, err)
-			}
-			defer jumpConn.Close()
-
-			newClient, err := createSshClient(
-				jumpConn,
-				jumpServerConf.SshHost,
-				jumpServerConf.SshPort,
-				jumpServerConf.SshAuthMethod,
-				jumpServerConf.SshUsername,
-				jumpServerConf.SshPassword,
-				jumpServerConf.SshKey,
-				jumpServerConf.SshKeyPassphrase,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create jump server ssh client[%d]: %w", i+1, err)
-			}
-			defer newClient.Close()
-
-			jumpClient = newClient
-			d.logger.Info(fmt.Sprintf("jump server connected [%d]", i+1), slog.String("host", jumpServerConf.SshHost))
-		}
-
-		// 通过跳板机发起 TCP 连接到目标服务器
-		targetConn, err = jumpClient.DialContext(ctx, "tcp", net.JoinHostPort(d.config.SshHost, strconv.Itoa(int(d.config.SshPort))))
-		if err != nil {
-			return nil, fmt.Errorf("failed to connect to target server: %w", err)
-		}
-	} else {
-		// 直接发起 TCP 连接到目标服务器
-		targetConn, err = net.Dial("tcp", net.JoinHostPort(d.config.SshHost, strconv.Itoa(int(d.config.SshPort))))
-		if err != nil {
-			return nil, fmt.Errorf("failed to connect to target server: %w", err)
+	// 连接到目标服务器（如有跳板机，则经由跳板机）
+	jumpServers := make([]xssh.ServerConfig, len(d.config.JumpServers))
+	for i, jumpServerConf := range d.config.JumpServers {
+		jumpServers[i] = xssh.ServerConfig{
+			Host:          jumpServerConf.SshHost,
+			Port:          jumpServerConf.SshPort,
+			AuthMethod:    jumpServerConf.SshAuthMethod,
+			Username:      jumpServerConf.SshUsername,
+			Password:      jumpServerConf.SshPassword,
+			Key:           jumpServerConf.SshKey,
+			KeyPassphrase: jumpServerConf.SshKeyPassphrase,
+
+			HostKeyVerifyMode:   d.config.SshHostKeyVerifyMode,
+			HostKeyFingerprints: strings.Split(jumpServerConf.SshHostKeyFingerprint, ";"),
+			KnownHosts:          d.config.SshKnownHosts,
+			OnHostKeyTrusted:    d.onHostKeyTrusted(i + 1),
 		}
 	}
-	defer targetConn.Close()
-
-	// 通过已有的连接创建目标服务器 SSH 客户端
-	client, err := createSshClient(
-		targetConn,
-		d.config.SshHost,
-		d.config.SshPort,
-		d.config.SshAuthMethod,
-		d.config.SshUsername,
-		d.config.SshPassword,
-		d.config.SshKey,
-		d.config.SshKeyPassphrase,
-	)
+	client, err := xssh.Dial(ctx, xssh.ServerConfig{
+		Host:          d.config.SshHost,
+		Port:          d.config.SshPort,
+		AuthMethod:    d.config.SshAuthMethod,
+		Username:      d.config.SshUsername,
+		Password:      d.config.SshPassword,
+		Key:           d.config.SshKey,
+		KeyPassphrase: d.config.SshKeyPassphrase,
+
+		HostKeyVerifyMode:   d.config.SshHostKeyVerifyMode,
+		HostKeyFingerprints: strings.Split(d.config.SshHostKeyFingerprint, ";"),
+		KnownHosts:          d.config.SshKnownHosts,
+		OnHostKeyTrusted:    d.onHostKeyTrusted(0),
+	}, jumpServers)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create ssh client: %w", err)
+		return nil, err
 	}
 	defer client.Close()
 
 	d.logger.Info("ssh connected")
 
+	writer, err := newFileWriter(d.config, client)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _err != nil && len(writer.files) > 0 {
+			_err = d.rollback(ctx, client, writer, _err)
+			_res = &core.SSLDeployResult{ExtendedData: writer.extendedData()}
+		}
+	}()
+
 	// 执行前置命令
 	if d.config.PreCommand != "" {
-		stdout, stderr, err := execSshCommand(client, d.config.PreCommand)
+		stdout, stderr, err := client.ExecCommandContext(ctx, d.config.PreCommand)
 		d.logger.Debug("run pre-command", slog.String("stdout", stdout), slog.String("stderr", stderr))
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute pre-command (stdout: %s, stderr: %s): %w ", stdout, stderr, err)
@@ -212,26 +248,26 @@ func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privke
 	// 上传证书和私钥文件
 	switch d.config.OutputFormat {
 	case OUTPUT_FORMAT_PEM:
-		if err := writeFileString(client, d.config.UseSCP, d.config.OutputCertPath, certPEM); err != nil {
+		if err := writer.Write(ctx, d.config.OutputCertPath, []byte(certPEM)); err != nil {
 			return nil, fmt.Errorf("failed to upload certificate file: %w", err)
 		}
 		d.logger.Info("ssl certificate file uploaded", slog.String("path", d.config.OutputCertPath))
 
 		if d.config.OutputServerCertPath != "" {
-			if err := writeFileString(client, d.config.UseSCP, d.config.OutputServerCertPath, serverCertPEM); err != nil {
+			if err := writer.Write(ctx, d.config.OutputServerCertPath, []byte(serverCertPEM)); err != nil {
 				return nil, fmt.Errorf("failed to save server certificate file: %w", err)
 			}
 			d.logger.Info("ssl server certificate file uploaded", slog.String("path", d.config.OutputServerCertPath))
 		}
 
 		if d.config.OutputIntermediaCertPath != "" {
-			if err := writeFileString(client, d.config.UseSCP, d.config.OutputIntermediaCertPath, intermediaCertPEM); err != nil {
+			if err := writer.Write(ctx, d.config.OutputIntermediaCertPath, []byte(intermediaCertPEM)); err != nil {
 				return nil, fmt.Errorf("failed to save intermedia certificate file: %w", err)
 			}
 			d.logger.Info("ssl intermedia certificate file uploaded", slog.String("path", d.config.OutputIntermediaCertPath))
 		}
 
-		if err := writeFileString(client, d.config.UseSCP, d.config.OutputKeyPath, privkeyPEM); err != nil {
+		if err := writer.Write(ctx, d.config.OutputKeyPath, []byte(privkeyPEM)); err != nil {
 			return nil, fmt.Errorf("failed to upload private key file: %w", err)
 		}
 		d.logger.Info("ssl private key file uploaded", slog.String("path", d.config.OutputKeyPath))
@@ -243,7 +279,7 @@ func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privke
 		}
 		d.logger.Info("ssl certificate transformed to pfx")
 
-		if err := writeFile(client, d.config.UseSCP, d.config.OutputCertPath, pfxData); err != nil {
+		if err := writer.Write(ctx, d.config.OutputCertPath, pfxData); err != nil {
 			return nil, fmt.Errorf("failed to upload certificate file: %w", err)
 		}
 		d.logger.Info("ssl certificate file uploaded", slog.String("path", d.config.OutputCertPath))
@@ -255,7 +291,7 @@ func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privke
 		}
 		d.logger.Info("ssl certificate transformed to jks")
 
-		if err := writeFile(client, d.config.UseSCP, d.config.OutputCertPath, jksData); err != nil {
+		if err := writer.Write(ctx, d.config.OutputCertPath, jksData); err != nil {
 			return nil, fmt.Errorf("failed to upload certificate file: %w", err)
 		}
 		d.logger.Info("ssl certificate file uploaded", slog.String("path", d.config.OutputCertPath))
@@ -266,173 +302,381 @@ func (d *SSLDeployerProvider) Deploy(ctx context.Context, certPEM string, privke
 
 	// 执行后置命令
 	if d.config.PostCommand != "" {
-		stdout, stderr, err := execSshCommand(client, d.config.PostCommand)
+		stdout, stderr, err := client.ExecCommandContext(ctx, d.config.PostCommand)
 		d.logger.Debug("run post-command", slog.String("stdout", stdout), slog.String("stderr", stderr))
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute post-command (stdout: %s, stderr: %s): %w ", stdout, stderr, err)
 		}
 	}
 
-	return &core.SSLDeployResult{}, nil
+	// 部署成功后删除备份文件，以免私钥等文件的副本残留
+	if err := writer.RemoveBackups(); err != nil {
+		d.logger.Warn("failed to remove backup files", slog.Any("error", err))
+	}
+
+	return &core.SSLDeployResult{ExtendedData: writer.extendedData()}, nil
 }
 
-func createSshClient(conn net.Conn, host string, port int32, authMethod string, username, password, key, keyPassphrase string) (*ssh.Client, error) {
-	if host == "" {
-		host = "localhost"
+func (d *SSLDeployerProvider) rollback(ctx context.Context, client *xssh.Client, writer *fileWriter, cause error) error {
+	if !d.config.BackupAndRollback {
+		return cause
 	}
 
-	if port == 0 {
-		port = 22
+	if err := writer.Rollback(); err != nil {
+		d.logger.Warn("failed to roll back remote files", slog.Any("error", err))
+		return errors.Join(cause, fmt.Errorf("failed to roll back remote files: %w", err))
 	}
 
-	if username == "" {
-		username = "root"
+	d.logger.Info("remote files rolled back")
+	cause = fmt.Errorf("%w (remote files have been rolled back)", cause)
+
+	// 重新执行后置命令，以使服务重新加载已恢复的文件。
+	// 此时上下文可能已被取消（如节点超时或多主机部署快速失败），因此使用独立的超时时间。
+	if d.config.PostCommand != "" {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackPostCommandTimeout)
+		defer cancel()
+
+		stdout, stderr, err := client.ExecCommandContext(ctx, d.config.PostCommand)
+		d.logger.Debug("re-run post-command after rollback", slog.String("stdout", stdout), slog.String("stderr", stderr))
+		if err != nil {
+			d.logger.Warn("failed to re-run post-command after rollback", slog.Any("error", err))
+			return errors.Join(cause, fmt.Errorf("failed to re-run post-command after rollback (stdout: %s, stderr: %s): %w", stdout, stderr, err))
+		}
 	}
 
-	const AUTH_METHOD_NONE = "none"
-	const AUTH_METHOD_PASSWORD = "password"
-	const AUTH_METHOD_KEY = "key"
-	if authMethod == "" {
-		if key != "" {
-			authMethod = AUTH_METHOD_KEY
-		} else if password != "" {
-			authMethod = AUTH_METHOD_PASSWORD
-		} else {
-			authMethod = AUTH_METHOD_NONE
+	return cause
+}
+
+type hostDeployResult struct {
+	Host         string         `json:"host"`
+	Port         int32          `json:"port,omitempty"`
+	Success      bool           `json:"success"`
+	Skipped      bool           `json:"skipped,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	Duration     int64          `json:"duration"`
+	ExtendedData map[string]any `json:"extendedData,omitempty"`
+}
+
+func (d *SSLDeployerProvider) deployToHosts(ctx context.Context, certPEM string, privkeyPEM string) (*core.SSLDeployResult, error) {
+	// 主目标服务器（如已指定）视为第 0 个主机，额外目标主机依次视为第 1~N 个主机
+	hosts := make([]HostConfig, 0, len(d.config.Hosts)+1)
+	hostIndexes := make([]int, 0, len(d.config.Hosts)+1)
+	if d.config.SshHost != "" {
+		hosts = append(hosts, HostConfig{SshHost: d.config.SshHost, SshPort: d.config.SshPort, SshHostKeyFingerprint: d.config.SshHostKeyFingerprint})
+		hostIndexes = append(hostIndexes, 0)
+	}
+	for i, host := range d.config.Hosts {
+		if host.SshPort == 0 {
+			host.SshPort = d.config.SshPort
 		}
+		hosts = append(hosts, host)
+		hostIndexes = append(hostIndexes, i+1)
+	}
+
+	failurePolicy := d.config.FailurePolicy
+	if failurePolicy == "" {
+		failurePolicy = FAILURE_POLICY_FAILFAST
+	}
+	switch failurePolicy {
+	case FAILURE_POLICY_FAILFAST, FAILURE_POLICY_BESTEFFORT, FAILURE_POLICY_RATIO:
+	default:
+		return nil, fmt.Errorf("unsupported failure policy '%s'", failurePolicy)
+	}
+
+	minSuccessPercent := d.config.MinSuccessPercent
+	if minSuccessPercent == 0 {
+		minSuccessPercent = 100
+	} else if minSuccessPercent < 0 || minSuccessPercent > 100 {
+		return nil, fmt.Errorf("invalid min success percent %d", minSuccessPercent)
 	}
 
-	authentications := make([]ssh.AuthMethod, 0)
-	switch authMethod {
-	case AUTH_METHOD_NONE:
-		{
+	parallelism := int(d.config.Parallelism)
+	if parallelism <= 0 {
+		parallelism = 5
+	}
+	parallelism = min(parallelism, len(hosts))
+
+	d.logger.Info("deploying to multiple hosts", slog.Int("hosts", len(hosts)), slog.Int("parallelism", parallelism), slog.String("failurePolicy", string(failurePolicy)))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*hostDeployResult, len(hosts))
+	semaphore := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+	for i, host := range hosts {
+		hostIndex := hostIndexes[i]
+		result := &hostDeployResult{Host: host.SshHost, Port: host.SshPort}
+		results[i] = result
+
+		hostConfig := *d.config
+		hostConfig.SshHost = host.SshHost
+		hostConfig.SshPort = host.SshPort
+		hostConfig.SshHostKeyFingerprint = host.SshHostKeyFingerprint
+		hostConfig.Hosts = nil
+		if d.config.OnSshHostKeyTrusted != nil {
+			// 回调可能被并发调用，需由调用方保证持久化时不会相互覆盖
+			hostConfig.OnSshHostKeyTrusted = func(_ int, jumpServerIndex int, fingerprint string) error {
+				return d.config.OnSshHostKeyTrusted(hostIndex, jumpServerIndex, fingerprint)
+			}
+		}
+		hostDeployer := &SSLDeployerProvider{
+			config: &hostConfig,
+			logger: d.logger.With(slog.String("host", host.SshHost)),
 		}
 
-	case AUTH_METHOD_PASSWORD:
-		{
-			authentications = append(authentications, ssh.Password(password))
-			authentications = append(authentications, ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
-				if len(questions) == 1 {
-					return []string{password}, nil
-				}
-				return nil, fmt.Errorf("unexpected keyboard interactive question [%s]", strings.Join(questions, ", "))
-			}))
+		// 按顺序派发，以便在快速失败时跳过尚未开始的主机
+		acquired := false
+		select {
+		case semaphore <- struct{}{}:
+			acquired = true
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			// 两个分支同时就绪时可能已取得名额，需归还
+			if acquired {
+				<-semaphore
+			}
+
+			result.Skipped = true
+			result.Error = "skipped due to previous failures"
+			continue
 		}
 
-	case AUTH_METHOD_KEY:
-		{
-			var signer ssh.Signer
-			var err error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
 
-			if keyPassphrase != "" {
-				signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(keyPassphrase))
-			} else {
-				signer, err = ssh.ParsePrivateKey([]byte(key))
+			startedAt := time.Now()
+			res, err := hostDeployer.Deploy(ctx, certPEM, privkeyPEM)
+			result.Duration = time.Since(startedAt).Milliseconds()
+			if res != nil {
+				result.ExtendedData = res.ExtendedData
 			}
-
 			if err != nil {
-				return nil, err
+				result.Error = err.Error()
+				hostDeployer.logger.Warn("failed to deploy to host", slog.Any("error", err))
+
+				if failurePolicy == FAILURE_POLICY_FAILFAST {
+					cancel()
+				}
+				return
 			}
 
-			authentications = append(authentications, ssh.PublicKeys(signer))
+			result.Success = true
+			hostDeployer.logger.Info("deployed to host")
+		}()
+	}
+	wg.Wait()
+
+	succeeded, failed, skipped := 0, 0, 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		} else if result.Skipped {
+			skipped++
+		} else {
+			failed++
+		}
+	}
+	d.logger.Info("multiple hosts deployment finished", slog.Int("succeeded", succeeded), slog.Int("failed", failed), slog.Int("skipped", skipped))
+
+	deployResult := &core.SSLDeployResult{
+		ExtendedData: map[string]any{
+			"hosts":     results,
+			"succeeded": succeeded,
+			"failed":    failed,
+			"skipped":   skipped,
+		},
+	}
+
+	switch failurePolicy {
+	case FAILURE_POLICY_FAILFAST:
+		if failed > 0 || skipped > 0 {
+			return deployResult, fmt.Errorf("failed to deploy to %d of %d hosts (%d skipped)", failed, len(hosts), skipped)
 		}
 
-	default:
-		return nil, fmt.Errorf("unsupported auth method '%s'", authMethod)
-	}
+	case FAILURE_POLICY_BESTEFFORT:
+		if succeeded == 0 {
+			return deployResult, fmt.Errorf("failed to deploy to all %d hosts", len(hosts))
+		}
 
-	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
-	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
-		User:            username,
-		Auth:            authentications,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	})
-	if err != nil {
-		return nil, err
+	case FAILURE_POLICY_RATIO:
+		if succeeded*100 < int(minSuccessPercent)*len(hosts) {
+			return deployResult, fmt.Errorf("only %d of %d hosts deployed, which is below the minimum success percent %d%%", succeeded, len(hosts), minSuccessPercent)
+		}
 	}
 
-	return ssh.NewClient(sshConn, chans, reqs), nil
+	return deployResult, nil
 }
 
-func execSshCommand(sshCli *ssh.Client, command string) (string, string, error) {
-	session, err := sshCli.NewSession()
-	if err != nil {
-		return "", "", err
+func (d *SSLDeployerProvider) onHostKeyTrusted(serverIndex int) func(fingerprint string) error {
+	if d.config.OnSshHostKeyTrusted == nil {
+		return nil
 	}
-	defer session.Close()
 
-	stdoutBuf := bytes.NewBuffer(nil)
-	session.Stdout = stdoutBuf
-	stderrBuf := bytes.NewBuffer(nil)
-	session.Stderr = stderrBuf
-	err = session.Run(command)
-	if err != nil {
-		return stdoutBuf.String(), stderrBuf.String(), fmt.Errorf("failed to execute ssh command: %w", err)
+	return func(fingerprint string) error {
+		if serverIndex == 0 {
+			d.logger.Info("ssh host key trusted on first use", slog.String("fingerprint", fingerprint))
+		} else {
+			d.logger.Info("ssh host key of jump server trusted on first use", slog.Int("jumpServerIndex", serverIndex), slog.String("fingerprint", fingerprint))
+		}
+
+		return d.config.OnSshHostKeyTrusted(0, serverIndex, fingerprint)
 	}
+}
 
-	return stdoutBuf.String(), stderrBuf.String(), nil
+// 回滚后重新执行后置命令的超时时间。
+const rollbackPostCommandTimeout = 5 * time.Minute
+
+type writtenFile struct {
+	Path       string `json:"path"`
+	BackupPath string `json:"backupPath,omitempty"`
+	Created    bool   `json:"created"`
+}
+
+// 记录部署过程中写入的远程文件，以便在需要时回滚。
+type fileWriter struct {
+	config      *SSLDeployerProviderConfig
+	client      *xssh.Client
+	mode        os.FileMode
+	files       []*writtenFile
+	rolledBack  bool
+	rollbackErr error
 }
 
-func writeFileString(sshCli *ssh.Client, useSCP bool, path string, content string) error {
-	if useSCP {
-		return writeFileStringWithSCP(sshCli, path, content)
+func newFileWriter(config *SSLDeployerProviderConfig, client *xssh.Client) (*fileWriter, error) {
+	writer := &fileWriter{
+		config: config,
+		client: client,
+		files:  make([]*writtenFile, 0),
+	}
+
+	if config.OutputFileMode != "" {
+		mode, err := strconv.ParseUint(config.OutputFileMode, 8, 32)
+		if err != nil || mode > 0o777 {
+			return nil, fmt.Errorf("invalid output file mode '%s'", config.OutputFileMode)
+		}
+		writer.mode = os.FileMode(mode)
 	}
 
-	return writeFileStringWithSFTP(sshCli, path, content)
+	return writer, nil
 }
 
-func writeFile(sshCli *ssh.Client, useSCP bool, path string, data []byte) error {
-	if useSCP {
-		return writeFileWithSCP(sshCli, path, data)
+func (w *fileWriter) Write(ctx context.Context, path string, data []byte) error {
+	// SFTP/SCP 文件操作无法中途取消，因此在每次写入前检查上下文
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	return writeFileWithSFTP(sshCli, path, data)
-}
+	useSCP := w.config.UseSCP
 
-func writeFileStringWithSCP(sshCli *ssh.Client, path string, content string) error {
-	return writeFileWithSCP(sshCli, path, []byte(content))
-}
+	// 同一文件被多次写入时，仅在首次写入前备份
+	if !slices.ContainsFunc(w.files, func(f *writtenFile) bool { return f.Path == path }) {
+		file := &writtenFile{Path: path}
 
-func writeFileWithSCP(sshCli *ssh.Client, path string, data []byte) error {
-	scpCli, err := scp.NewClientFromExistingSSH(sshCli, &scp.ClientOption{})
-	if err != nil {
-		return fmt.Errorf("failed to create scp client: %w", err)
+		if w.config.BackupAndRollback {
+			exists, err := w.client.FileExists(useSCP, path)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				file.BackupPath = newBackupPath(path)
+				if err := w.client.CopyFile(useSCP, path, file.BackupPath); err != nil {
+					return fmt.Errorf("failed to backup remote file: %w", err)
+				}
+			} else {
+				file.Created = true
+			}
+		}
+
+		// 先记录再写入，以便写入失败时也能回滚
+		w.files = append(w.files, file)
 	}
 
-	reader := bytes.NewReader(data)
-	err = scpCli.CopyToRemote(reader, path, &scp.FileTransferOption{})
-	if err != nil {
-		return fmt.Errorf("failed to write to remote file: %w", err)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if w.config.UseAtomicWrite {
+		if err := w.client.WriteFileAtomic(useSCP, path, data); err != nil {
+			return err
+		}
+	} else {
+		if err := w.client.WriteFile(useSCP, path, data); err != nil {
+			return err
+		}
+	}
+
+	if w.mode != 0 {
+		if err := w.client.ChmodFile(useSCP, path, w.mode); err != nil {
+			return err
+		}
+	}
+
+	if err := w.client.ChownFile(path, w.config.OutputFileOwner, w.config.OutputFileGroup); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func writeFileStringWithSFTP(sshCli *ssh.Client, path string, content string) error {
-	return writeFileWithSFTP(sshCli, path, []byte(content))
+// 返回唯一的备份文件路径，以免覆盖或删除用户自行创建的备份文件（如 "*.bak"）。
+func newBackupPath(path string) string {
+	return fmt.Sprintf("%s.certimate-bak-%d", path, time.Now().UnixNano())
 }
 
-func writeFileWithSFTP(sshCli *ssh.Client, path string, data []byte) error {
-	sftpCli, err := sftp.NewClient(sshCli)
-	if err != nil {
-		return fmt.Errorf("failed to create sftp client: %w", err)
-	}
-	defer sftpCli.Close()
+func (w *fileWriter) Rollback() error {
+	var errs []error
+
+	for i := len(w.files) - 1; i >= 0; i-- {
+		file := w.files[i]
 
-	if err := sftpCli.MkdirAll(filepath.ToSlash(filepath.Dir(path))); err != nil {
-		return fmt.Errorf("failed to create remote directory: %w", err)
+		if file.BackupPath != "" {
+			if err := w.client.RenameFile(w.config.UseSCP, file.BackupPath, file.Path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore remote file '%s': %w", file.Path, err))
+			}
+		} else if file.Created {
+			if err := w.client.RemoveFile(w.config.UseSCP, file.Path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove remote file '%s': %w", file.Path, err))
+			}
+		}
 	}
 
-	file, err := sftpCli.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
-	if err != nil {
-		return fmt.Errorf("failed to open remote file: %w", err)
+	w.rolledBack = true
+	w.rollbackErr = errors.Join(errs...)
+	return w.rollbackErr
+}
+
+func (w *fileWriter) RemoveBackups() error {
+	var errs []error
+
+	for _, file := range w.files {
+		if file.BackupPath == "" {
+			continue
+		}
+
+		if err := w.client.RemoveFile(w.config.UseSCP, file.BackupPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove remote backup file '%s': %w", file.BackupPath, err))
+			continue
+		}
+
+		file.BackupPath = ""
 	}
-	defer file.Close()
 
-	_, err = file.Write(data)
-	if err != nil {
-		return fmt.Errorf("failed to write to remote file: %w", err)
+	return errors.Join(errs...)
+}
+
+func (w *fileWriter) extendedData() map[string]any {
+	data := map[string]any{
+		"files":      w.files,
+		"rolledBack": w.rolledBack && w.rollbackErr == nil,
+	}
+	if w.rollbackErr != nil {
+		data["rollbackError"] = w.rollbackErr.Error()
 	}
 
-	return nil
+	return data
 }
@@ -0,0 +1,38 @@
+package acmedns
+
+import (
+	"context"
+	"net/http"
+)
+
+type RegisterRequest struct {
+	AllowFrom []string `json:"allowfrom,omitempty"`
+}
+
+type RegisterResponse struct {
+	apiResponseBase
+	Account
+}
+
+func (c *Client) Register(req *RegisterRequest) (*RegisterResponse, error) {
+	return c.RegisterWithContext(context.Background(), req)
+}
+
+func (c *Client) RegisterWithContext(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+	httpreq, err := c.newRequest(http.MethodPost, "/register")
+	if err != nil {
+		return nil, err
+	} else {
+		if req != nil {
+			httpreq.SetBody(req)
+		}
+		httpreq.SetContext(ctx)
+	}
+
+	result := &RegisterResponse{}
+	if _, err := c.doRequestWithResult(httpreq, result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package acmedns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type UpdateRequest struct {
+	Username  string `json:"-"`
+	Password  string `json:"-"`
+	Subdomain string `json:"subdomain"`
+	Txt       string `json:"txt"`
+}
+
+type UpdateResponse struct {
+	apiResponseBase
+	Txt string `json:"txt"`
+}
+
+func (c *Client) Update(req *UpdateRequest) (*UpdateResponse, error) {
+	return c.UpdateWithContext(context.Background(), req)
+}
+
+func (c *Client) UpdateWithContext(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("sdkerr: nil request")
+	}
+
+	httpreq, err := c.newRequest(http.MethodPost, "/update")
+	if err != nil {
+		return nil, err
+	} else {
+		httpreq.SetHeader("X-Api-User", req.Username)
+		httpreq.SetHeader("X-Api-Key", req.Password)
+		httpreq.SetBody(req)
+		httpreq.SetContext(ctx)
+	}
+
+	result := &UpdateResponse{}
+	if _, err := c.doRequestWithResult(httpreq, result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,28 @@
+package acmedns
+
+type apiResponse interface {
+	GetError() string
+}
+
+type apiResponseBase struct {
+	Error *string `json:"error,omitempty"`
+}
+
+func (r *apiResponseBase) GetError() string {
+	if r.Error == nil {
+		return ""
+	}
+
+	return *r.Error
+}
+
+var _ apiResponse = (*apiResponseBase)(nil)
+
+// acme-dns 账户，与 acme-dns-client 及 lego 的凭据存储文件格式一致。
+type Account struct {
+	Username   string   `json:"username"`
+	Password   string   `json:"password"`
+	FullDomain string   `json:"fulldomain"`
+	Subdomain  string   `json:"subdomain"`
+	AllowFrom  []string `json:"allowfrom,omitempty"`
+}
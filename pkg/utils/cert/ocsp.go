@@ -0,0 +1,79 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// 表示 OCSP 查询结果的数据结构。
+type OCSPStatus struct {
+	// 证书状态。
+	// 可取值 [ocsp.Good]、[ocsp.Revoked] 或 [ocsp.Unknown]。
+	Status int
+	// 吊销原因代码。
+	// 仅当证书状态为 [ocsp.Revoked] 时有意义。
+	RevocationReason int
+}
+
+// 向证书中指定的 OCSP 服务器查询证书的吊销状态。
+//
+// 入参:
+//   - ctx: 上下文。
+//   - cert: 待查询的 x509.Certificate 对象。
+//   - issuer: 颁发者 x509.Certificate 对象。
+//
+// 出参:
+//   - status: OCSP 查询结果。
+//   - err: 错误。
+func QueryOCSPStatus(ctx context.Context, cert, issuer *x509.Certificate) (_status *OCSPStatus, _err error) {
+	if cert == nil || issuer == nil {
+		return nil, errors.New("certificate or issuer is nil")
+	}
+	if len(cert.OCSPServer) == 0 {
+		return nil, errors.New("certificate does not specify an ocsp server")
+	}
+
+	ocspReq, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ocsp request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cert.OCSPServer[0], bytes.NewReader(ocspReq))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	req.Header.Set("Accept", "application/ocsp-response")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send ocsp request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected ocsp response status code: %d", resp.StatusCode)
+	}
+
+	respBytes, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ocsp response: %w", err)
+	}
+
+	ocspResp, err := ocsp.ParseResponseForCert(respBytes, cert, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ocsp response: %w", err)
+	}
+
+	return &OCSPStatus{
+		Status:           ocspResp.Status,
+		RevocationReason: ocspResp.RevocationReason,
+	}, nil
+}
@@ -37,6 +37,35 @@ func ParseCertificateFromPEM(certPEM string) (_cert *x509.Certificate, _err erro
 	return cert, nil
 }
 
+// 从 PEM 编码的证书签名请求字符串解析并返回一个 x509.CertificateRequest 对象。
+// 解析后会校验 CSR 的签名。
+//
+// 入参:
+//   - csrPEM: 证书签名请求 PEM 内容。
+//
+// 出参:
+//   - csr: x509.CertificateRequest 对象。
+//   - err: 错误。
+func ParseCertificateRequestFromPEM(csrPEM string) (_csr *x509.CertificateRequest, _err error) {
+	pemData := []byte(csrPEM)
+
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate request: %w", err)
+	}
+
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("failed to verify certificate request signature: %w", err)
+	}
+
+	return csr, nil
+}
+
 // 从 PEM 编码的私钥字符串解析并返回一个 crypto.PrivateKey 对象。
 //
 // 入参:
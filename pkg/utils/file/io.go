@@ -2,8 +2,11 @@ package file
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strconv"
 )
 
 // 与 [Write] 类似，但写入的是字符串内容。
@@ -49,3 +52,151 @@ func Write(path string, data []byte) error {
 
 	return nil
 }
+
+// 以原子方式将数据写入指定路径的文件。
+// 先写入同目录下的临时文件，再将其重命名为目标文件，以避免目标文件处于写入一半的状态。
+// 如果目录不存在，将会递归创建目录。
+// 如果目标文件已存在，新文件将沿用其权限、所有者和所属组；否则权限为 0644。
+//
+// 入参:
+//   - path: 文件路径。
+//   - data: 文件数据字节数组。
+//
+// 出参:
+//   - 错误。
+func WriteAtomic(path string, data []byte) (_err error) {
+	dir := filepath.Dir(path)
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	mode := os.FileMode(0o644)
+	fileInfo, err := os.Stat(path)
+	if err == nil {
+		mode = fileInfo.Mode().Perm()
+	} else {
+		fileInfo = nil
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		if _err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := file.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to change temporary file mode: %w", err)
+	}
+	if fileInfo != nil {
+		if err := chownLike(file, fileInfo); err != nil {
+			return fmt.Errorf("failed to change temporary file owner: %w", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// 复制文件，并保留其权限、所有者和所属组。
+// 如果目标文件已存在，将会覆盖目标文件。
+//
+// 入参:
+//   - srcPath: 源文件路径。
+//   - dstPath: 目标文件路径。
+//
+// 出参:
+//   - 错误。
+func Copy(srcPath string, dstPath string) error {
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer srcFile.Close()
+
+	srcFileInfo, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	dstFile, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, srcFileInfo.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	if err := dstFile.Chmod(srcFileInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to change file mode: %w", err)
+	}
+
+	if err := chownLike(dstFile, srcFileInfo); err != nil {
+		return fmt.Errorf("failed to change file owner: %w", err)
+	}
+
+	return nil
+}
+
+// 修改文件的所有者和所属组。
+//
+// 入参:
+//   - path: 文件路径。
+//   - owner: 所有者用户名或用户 ID。为空时不修改。
+//   - group: 所属组名或组 ID。为空时不修改。
+//
+// 出参:
+//   - 错误。
+func Chown(path string, owner string, group string) error {
+	uid, gid := -1, -1
+
+	if owner != "" {
+		if id, err := strconv.Atoi(owner); err == nil {
+			uid = id
+		} else if u, err := user.Lookup(owner); err != nil {
+			return fmt.Errorf("failed to lookup user '%s': %w", owner, err)
+		} else if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return fmt.Errorf("failed to parse uid of user '%s': %w", owner, err)
+		}
+	}
+
+	if group != "" {
+		if id, err := strconv.Atoi(group); err == nil {
+			gid = id
+		} else if g, err := user.LookupGroup(group); err != nil {
+			return fmt.Errorf("failed to lookup group '%s': %w", group, err)
+		} else if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return fmt.Errorf("failed to parse gid of group '%s': %w", group, err)
+		}
+	}
+
+	if uid == -1 && gid == -1 {
+		return nil
+	}
+
+	if err := os.Chown(path, uid, gid); err != nil {
+		return fmt.Errorf("failed to change file owner: %w", err)
+	}
+
+	return nil
+}
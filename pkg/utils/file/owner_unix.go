@@ -0,0 +1,27 @@
+//go:build !windows
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+func chownLike(file *os.File, srcFileInfo os.FileInfo) error {
+	srcStat, ok := srcFileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// 所有者和所属组已一致时无需修改，以免非特权用户因无权执行 chown 而失败
+	if stat, ok := fileInfo.Sys().(*syscall.Stat_t); ok && stat.Uid == srcStat.Uid && stat.Gid == srcStat.Gid {
+		return nil
+	}
+
+	return file.Chown(int(srcStat.Uid), int(srcStat.Gid))
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package file
+
+import (
+	"os"
+)
+
+func chownLike(file *os.File, srcFileInfo os.FileInfo) error {
+	// Windows 下文件所有权由 ACL 管理，新文件会继承所在目录的 ACL，无需处理
+	return nil
+}
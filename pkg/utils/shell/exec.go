@@ -0,0 +1,75 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// Shell 执行环境。
+type ShellEnvType string
+
+const (
+	SHELL_ENV_SH         = ShellEnvType("sh")
+	SHELL_ENV_CMD        = ShellEnvType("cmd")
+	SHELL_ENV_POWERSHELL = ShellEnvType("powershell")
+)
+
+// 创建在指定 Shell 执行环境中运行命令的 [exec.Cmd]。
+// 零值的执行环境根据操作系统决定。
+//
+// 入参:
+//   - ctx: 上下文。
+//   - shellEnv: Shell 执行环境。
+//   - command: 命令或脚本内容。
+//   - args: 额外参数。在 sh 中可通过 `$1`、`$2` 等位置参数获取；在 cmd 和 powershell 中追加到命令之后。
+//
+// 出参:
+//   - 命令对象。
+//   - 错误。
+func NewCommand(ctx context.Context, shellEnv ShellEnvType, command string, args ...string) (*exec.Cmd, error) {
+	if shellEnv == "" {
+		if runtime.GOOS == "windows" {
+			shellEnv = SHELL_ENV_CMD
+		} else {
+			shellEnv = SHELL_ENV_SH
+		}
+	}
+
+	switch shellEnv {
+	case SHELL_ENV_SH:
+		return exec.CommandContext(ctx, "sh", append([]string{"-c", command, "sh"}, args...)...), nil
+
+	case SHELL_ENV_CMD:
+		return exec.CommandContext(ctx, "cmd", append([]string{"/C", command}, args...)...), nil
+
+	case SHELL_ENV_POWERSHELL:
+		return exec.CommandContext(ctx, "powershell", append([]string{"-Command", command}, args...)...), nil
+	}
+
+	return nil, fmt.Errorf("unsupported shell env '%s'", shellEnv)
+}
+
+// 运行命令，并返回其标准输出和标准错误输出。
+//
+// 入参:
+//   - cmd: 命令对象。
+//
+// 出参:
+//   - 标准输出。
+//   - 标准错误输出。
+//   - 错误。
+func RunCommand(cmd *exec.Cmd) (string, string, error) {
+	stdoutBuf := bytes.NewBuffer(nil)
+	cmd.Stdout = stdoutBuf
+	stderrBuf := bytes.NewBuffer(nil)
+	cmd.Stderr = stderrBuf
+	err := cmd.Run()
+	if err != nil {
+		return stdoutBuf.String(), stderrBuf.String(), fmt.Errorf("failed to execute command: %w", err)
+	}
+
+	return stdoutBuf.String(), stderrBuf.String(), nil
+}
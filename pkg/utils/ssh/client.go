@@ -0,0 +1,1041 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/povsister/scp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	AUTH_METHOD_NONE     = "none"
+	AUTH_METHOD_PASSWORD = "password"
+	AUTH_METHOD_KEY      = "key"
+)
+
+const (
+	// 首次连接时信任并固定主机公钥，此后公钥不匹配时拒绝连接。
+	HOST_KEY_VERIFY_MODE_TOFU = "tofu"
+	// 仅信任已固定的主机公钥指纹或 known_hosts 中的主机公钥。
+	HOST_KEY_VERIFY_MODE_STRICT = "strict"
+	// 不校验主机公钥。
+	HOST_KEY_VERIFY_MODE_NONE = "none"
+)
+
+// 表示 SSH 服务器连接配置的数据结构。
+type ServerConfig struct {
+	// SSH 主机。
+	// 零值时默认值 "localhost"。
+	Host string
+	// SSH 端口。
+	// 零值时默认值 22。
+	Port int32
+	// SSH 认证方式。
+	// 可取值 "none"、"password" 或 "key"。
+	// 零值时根据有无密码或私钥字段决定。
+	AuthMethod string
+	// SSH 登录用户名。
+	// 零值时默认值 "root"。
+	Username string
+	// SSH 登录密码。
+	Password string
+	// SSH 登录私钥。
+	Key string
+	// SSH 登录私钥口令。
+	KeyPassphrase string
+	// SSH 主机公钥校验模式。
+	// 可取值 "tofu"、"strict" 或 "none"。
+	// 零值时默认值 "tofu"。
+	HostKeyVerifyMode string
+	// 已信任的 SSH 主机公钥指纹（SHA256 格式，形如 "SHA256:..."）。
+	HostKeyFingerprints []string
+	// 已信任的 SSH 主机公钥，known_hosts 文件格式。
+	KnownHosts string
+	// 首次连接时信任主机公钥后的回调，用于持久化主机公钥指纹。
+	// 仅在校验模式为 "tofu" 时生效；返回错误时将中止连接。
+	// 未设置时无法固定主机公钥，将按 "strict" 模式校验。
+	OnHostKeyTrusted func(fingerprint string) error
+	// SSH 握手（含登录认证）的超时时间。
+	// 零值时默认值 30 秒。
+	HandshakeTimeout time.Duration
+}
+
+// 表示 SSH 主机公钥校验失败的错误。
+type HostKeyError struct {
+	// 主机地址。
+	Addr string
+	// 主机公钥指纹。
+	Fingerprint string
+	// 是否为公钥不匹配（否则为未知主机）。
+	Mismatch bool
+}
+
+func (e *HostKeyError) Error() string {
+	if e.Mismatch {
+		return fmt.Sprintf("ssh host key mismatch for '%s': got '%s', which does not match the trusted host keys", e.Addr, e.Fingerprint)
+	}
+
+	return fmt.Sprintf("ssh host key for '%s' is unknown: got '%s', please approve it first", e.Addr, e.Fingerprint)
+}
+
+// 表示 SSH 客户端。
+// 关闭时会一并关闭经由的跳板机连接。
+type Client struct {
+	*ssh.Client
+
+	closers []io.Closer
+}
+
+// 连接到目标 SSH 服务器。
+// 如果指定了跳板机，将依次经由跳板机发起连接。
+//
+// 入参:
+//   - ctx: 上下文。
+//   - server: 目标服务器连接配置。
+//   - jumpServers: 跳板机连接配置数组。
+//
+// 出参:
+//   - client: SSH 客户端。
+//   - err: 错误。
+func Dial(ctx context.Context, server ServerConfig, jumpServers []ServerConfig) (_client *Client, _err error) {
+	client := &Client{closers: make([]io.Closer, 0)}
+	defer func() {
+		if _err != nil {
+			client.Close()
+		}
+	}()
+
+	targetConn, err := client.dialTargetConn(ctx, server, jumpServers)
+	if err != nil {
+		return nil, err
+	}
+
+	// 通过已有的连接创建目标服务器 SSH 客户端
+	targetClient, err := NewClientConn(targetConn, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ssh client: %w", err)
+	}
+
+	client.Client = targetClient
+	return client, nil
+}
+
+// 表示获取到的 SSH 主机公钥。
+type HostKey struct {
+	// 主机公钥。
+	PublicKey ssh.PublicKey
+	// 主机公钥指纹（SHA256 格式）。
+	Fingerprint string
+	// 是否已信任（即与已固定的主机公钥指纹或 known_hosts 匹配）。
+	Trusted bool
+	// 是否与已信任的主机公钥不匹配。
+	Mismatch bool
+}
+
+// 获取目标 SSH 服务器的主机公钥，而不进行登录认证。
+// 如果指定了跳板机，将依次经由跳板机发起连接，跳板机的主机公钥仍按其自身配置校验。
+//
+// 入参:
+//   - ctx: 上下文。
+//   - server: 目标服务器连接配置。
+//   - jumpServers: 跳板机连接配置数组。
+//
+// 出参:
+//   - 主机公钥。
+//   - 错误。
+func FetchHostKey(ctx context.Context, server ServerConfig, jumpServers []ServerConfig) (*HostKey, error) {
+	client := &Client{closers: make([]io.Closer, 0)}
+	defer client.Close()
+
+	targetConn, err := client.dialTargetConn(ctx, server, jumpServers)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以严格模式校验主机公钥，且不触发首次信任回调
+	server.HostKeyVerifyMode = HOST_KEY_VERIFY_MODE_STRICT
+	server.OnHostKeyTrusted = nil
+	hostKeyCallback, err := server.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取到主机公钥后即中止握手
+	var hostKey *HostKey
+	errHostKeyFetched := errors.New("host key fetched")
+	_, _, _, err = newClientConnWithTimeout(targetConn, server, &ssh.ClientConfig{
+		User: "root",
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hostKey = &HostKey{
+				PublicKey:   key,
+				Fingerprint: ssh.FingerprintSHA256(key),
+			}
+
+			var hostKeyErr *HostKeyError
+			if err := hostKeyCallback(hostname, remote, key); err == nil {
+				hostKey.Trusted = true
+			} else if errors.As(err, &hostKeyErr) {
+				hostKey.Mismatch = hostKeyErr.Mismatch
+			} else {
+				return err
+			}
+
+			return errHostKeyFetched
+		},
+	})
+	if hostKey == nil || !errors.Is(err, errHostKeyFetched) {
+		return nil, fmt.Errorf("failed to fetch ssh host key: %w", err)
+	}
+
+	return hostKey, nil
+}
+
+func (c *Client) dialTargetConn(ctx context.Context, server ServerConfig, jumpServers []ServerConfig) (net.Conn, error) {
+	var jumpClient *ssh.Client
+	for i, jumpServer := range jumpServers {
+		var jumpConn net.Conn
+		var err error
+
+		// 第一个连接是主机发起，后续通过跳板机发起
+		if jumpClient == nil {
+			jumpConn, err = (&net.Dialer{}).DialContext(ctx, "tcp", jumpServer.addr())
+		} else {
+			jumpConn, err = jumpClient.DialContext(ctx, "tcp", jumpServer.addr())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to jump server [%d]: %w", i+1, err)
+		}
+		c.closers = append(c.closers, jumpConn)
+
+		newClient, err := NewClientConn(jumpConn, jumpServer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create jump server ssh client[%d]: %w", i+1, err)
+		}
+		c.closers = append(c.closers, newClient)
+
+		jumpClient = newClient
+	}
+
+	var targetConn net.Conn
+	var err error
+	if jumpClient != nil {
+		// 通过跳板机发起 TCP 连接到目标服务器
+		targetConn, err = jumpClient.DialContext(ctx, "tcp", server.addr())
+	} else {
+		// 直接发起 TCP 连接到目标服务器
+		targetConn, err = (&net.Dialer{}).DialContext(ctx, "tcp", server.addr())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to target server: %w", err)
+	}
+	c.closers = append(c.closers, targetConn)
+
+	return targetConn, nil
+}
+
+// 通过已有的网络连接创建 SSH 客户端。
+//
+// 入参:
+//   - conn: 网络连接。
+//   - server: 服务器连接配置。
+//
+// 出参:
+//   - client: SSH 客户端。
+//   - err: 错误。
+func NewClientConn(conn net.Conn, server ServerConfig) (*ssh.Client, error) {
+	username := server.Username
+	if username == "" {
+		username = "root"
+	}
+
+	authMethod := server.AuthMethod
+	if authMethod == "" {
+		if server.Key != "" {
+			authMethod = AUTH_METHOD_KEY
+		} else if server.Password != "" {
+			authMethod = AUTH_METHOD_PASSWORD
+		} else {
+			authMethod = AUTH_METHOD_NONE
+		}
+	}
+
+	authentications := make([]ssh.AuthMethod, 0)
+	switch authMethod {
+	case AUTH_METHOD_NONE:
+		{
+		}
+
+	case AUTH_METHOD_PASSWORD:
+		{
+			password := server.Password
+			authentications = append(authentications, ssh.Password(password))
+			authentications = append(authentications, ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				if len(questions) == 1 {
+					return []string{password}, nil
+				}
+				return nil, fmt.Errorf("unexpected keyboard interactive question [%s]", strings.Join(questions, ", "))
+			}))
+		}
+
+	case AUTH_METHOD_KEY:
+		{
+			var signer ssh.Signer
+			var err error
+
+			if server.KeyPassphrase != "" {
+				signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(server.Key), []byte(server.KeyPassphrase))
+			} else {
+				signer, err = ssh.ParsePrivateKey([]byte(server.Key))
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			authentications = append(authentications, ssh.PublicKeys(signer))
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported auth method '%s'", authMethod)
+	}
+
+	hostKeyCallback, err := server.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	sshConn, chans, reqs, err := newClientConnWithTimeout(conn, server, &ssh.ClientConfig{
+		User:            username,
+		Auth:            authentications,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+func newClientConnWithTimeout(conn net.Conn, server ServerConfig, config *ssh.ClientConfig) (ssh.Conn, <-chan ssh.NewChannel, <-chan *ssh.Request, error) {
+	timeout := server.HandshakeTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	// 经由跳板机建立的连接不支持设置读写截止时间，因此超时后直接关闭连接以中止握手
+	timer := time.AfterFunc(timeout, func() { conn.Close() })
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, server.addr(), config)
+	if !timer.Stop() {
+		if err == nil {
+			sshConn.Close()
+		}
+		return nil, nil, nil, fmt.Errorf("ssh handshake with '%s' timed out after %s", server.addr(), timeout)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return sshConn, chans, reqs, nil
+}
+
+// 关闭 SSH 客户端及其经由的全部连接。
+//
+// 出参:
+//   - 错误。
+func (c *Client) Close() error {
+	var errs []error
+
+	if c.Client != nil {
+		if err := c.Client.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		if err := c.closers[i].Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	c.closers = nil
+
+	return errors.Join(errs...)
+}
+
+// 在远程服务器上执行命令。
+//
+// 入参:
+//   - command: 命令。
+//
+// 出参:
+//   - stdout: 标准输出。
+//   - stderr: 标准错误输出。
+//   - err: 错误。
+func (c *Client) ExecCommand(command string) (_stdout string, _stderr string, _err error) {
+	return c.ExecCommandContext(context.Background(), command)
+}
+
+// 与 [Client.ExecCommand] 类似，但可通过上下文中止命令。
+// 上下文取消或超时时将关闭会话，远程命令随之收到 SIGHUP，SSH 客户端本身不受影响。
+//
+// 入参:
+//   - ctx: 上下文。
+//   - command: 命令。
+//
+// 出参:
+//   - stdout: 标准输出。
+//   - stderr: 标准错误输出。
+//   - err: 错误。
+func (c *Client) ExecCommandContext(ctx context.Context, command string) (_stdout string, _stderr string, _err error) {
+	if err := ctx.Err(); err != nil {
+		return "", "", err
+	}
+
+	session, err := c.Client.NewSession()
+	if err != nil {
+		return "", "", err
+	}
+	defer session.Close()
+
+	stdoutBuf := &syncBuffer{}
+	session.Stdout = stdoutBuf
+	stderrBuf := &syncBuffer{}
+	session.Stderr = stderrBuf
+	if err := session.Start(command); err != nil {
+		return "", "", fmt.Errorf("failed to execute ssh command: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		session.Close()
+		return stdoutBuf.String(), stderrBuf.String(), fmt.Errorf("failed to execute ssh command: %w", context.Cause(ctx))
+	}
+	if err != nil {
+		return stdoutBuf.String(), stderrBuf.String(), fmt.Errorf("failed to execute ssh command: %w", err)
+	}
+
+	return stdoutBuf.String(), stderrBuf.String(), nil
+}
+
+// 与 [Client.WriteFile] 类似，但写入的是字符串内容。
+//
+// 入参:
+//   - useSCP: 是否使用 SCP 而非 SFTP。
+//   - path: 远程文件路径。
+//   - content: 文件内容。
+//
+// 出参:
+//   - 错误。
+func (c *Client) WriteFileString(useSCP bool, path string, content string) error {
+	return c.WriteFile(useSCP, path, []byte(content))
+}
+
+// 将数据写入远程服务器上指定路径的文件。
+// 如果文件已存在，将会覆盖原有内容。
+//
+// 入参:
+//   - useSCP: 是否使用 SCP 而非 SFTP。
+//   - path: 远程文件路径。
+//   - data: 文件数据字节数组。
+//
+// 出参:
+//   - 错误。
+func (c *Client) WriteFile(useSCP bool, path string, data []byte) error {
+	if useSCP {
+		return c.writeFileWithSCP(path, data)
+	}
+
+	return c.writeFileWithSFTP(path, data)
+}
+
+// 删除远程服务器上指定路径的文件。
+// 如果文件不存在，不会返回错误。
+//
+// 入参:
+//   - useSCP: 是否使用 SCP 而非 SFTP。
+//   - path: 远程文件路径。
+//
+// 出参:
+//   - 错误。
+func (c *Client) RemoveFile(useSCP bool, path string) error {
+	if useSCP {
+		// SCP 协议不支持删除文件，回退为执行 Shell 命令
+		if _, stderr, err := c.ExecCommand(fmt.Sprintf("rm -f %s", quoteShellArg(path))); err != nil {
+			return fmt.Errorf("failed to remove remote file (stderr: %s): %w", stderr, err)
+		}
+
+		return nil
+	}
+
+	sftpCli, err := sftp.NewClient(c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create sftp client: %w", err)
+	}
+	defer sftpCli.Close()
+
+	if err := sftpCli.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove remote file: %w", err)
+	}
+
+	return nil
+}
+
+// 以原子方式将数据写入远程服务器上指定路径的文件。
+// 先写入同目录下的临时文件，再将其重命名为目标文件，以避免目标文件处于写入一半的状态。
+// 如果目标文件已存在，临时文件将沿用其权限、所有者和所属组；否则权限为 0644。
+//
+// 入参:
+//   - useSCP: 是否使用 SCP 而非 SFTP。
+//   - path: 远程文件路径。
+//   - data: 文件数据字节数组。
+//
+// 出参:
+//   - 错误。
+func (c *Client) WriteFileAtomic(useSCP bool, path string, data []byte) (_err error) {
+	attrs, err := c.statFileAttrs(useSCP, path)
+	if err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0o644)
+	if attrs != nil {
+		perm = attrs.Mode
+	}
+
+	// 创建临时文件时即设置权限，以免如私钥等文件在写入后、修改权限前被其他用户读取
+	tempPath := path + ".certimate-tmp"
+	if useSCP {
+		// 远程 scp 不会修改已存在文件的权限，因此写入后再显式设置一次
+		err = c.writeFileWithSCPPerm(tempPath, data, perm)
+		if err == nil {
+			err = c.ChmodFile(useSCP, tempPath, perm)
+		}
+	} else {
+		err = c.writeFileWithSFTPPerm(tempPath, data, perm)
+	}
+	if err != nil {
+		c.RemoveFile(useSCP, tempPath)
+		return err
+	}
+	defer func() {
+		if _err != nil {
+			c.RemoveFile(useSCP, tempPath)
+		}
+	}()
+
+	if attrs != nil {
+		if err := c.chownFileByID(useSCP, tempPath, attrs.Uid, attrs.Gid); err != nil {
+			return err
+		}
+	}
+
+	return c.RenameFile(useSCP, tempPath, path)
+}
+
+// 将远程服务器上的文件重命名为指定路径。
+// 如果目标文件已存在，将会覆盖目标文件。
+//
+// 入参:
+//   - useSCP: 是否使用 SCP 而非 SFTP。
+//   - oldPath: 原远程文件路径。
+//   - newPath: 新远程文件路径。
+//
+// 出参:
+//   - 错误。
+func (c *Client) RenameFile(useSCP bool, oldPath string, newPath string) error {
+	if useSCP {
+		// SCP 协议不支持重命名文件，回退为执行 Shell 命令
+		if _, stderr, err := c.ExecCommand(fmt.Sprintf("mv -f %s %s", quoteShellArg(oldPath), quoteShellArg(newPath))); err != nil {
+			return fmt.Errorf("failed to rename remote file (stderr: %s): %w", stderr, err)
+		}
+
+		return nil
+	}
+
+	sftpCli, err := sftp.NewClient(c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create sftp client: %w", err)
+	}
+	defer sftpCli.Close()
+
+	// 优先使用 OpenSSH 的 posix-rename 扩展，以原子方式覆盖目标文件
+	if _, ok := sftpCli.HasExtension("posix-rename@openssh.com"); ok {
+		if err := sftpCli.PosixRename(oldPath, newPath); err != nil {
+			return fmt.Errorf("failed to rename remote file: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := sftpCli.Remove(newPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove remote file: %w", err)
+	}
+	if err := sftpCli.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to rename remote file: %w", err)
+	}
+
+	return nil
+}
+
+// 复制远程服务器上的文件，并保留其权限、所有者和所属组。
+// 如果目标文件已存在，将会覆盖目标文件。
+//
+// 入参:
+//   - useSCP: 是否使用 SCP 而非 SFTP。
+//   - srcPath: 源远程文件路径。
+//   - dstPath: 目标远程文件路径。
+//
+// 出参:
+//   - 错误。
+func (c *Client) CopyFile(useSCP bool, srcPath string, dstPath string) error {
+	if useSCP {
+		// SCP 协议不支持复制远程文件，回退为执行 Shell 命令
+		if _, stderr, err := c.ExecCommand(fmt.Sprintf("cp -pf %s %s", quoteShellArg(srcPath), quoteShellArg(dstPath))); err != nil {
+			return fmt.Errorf("failed to copy remote file (stderr: %s): %w", stderr, err)
+		}
+
+		return nil
+	}
+
+	sftpCli, err := sftp.NewClient(c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create sftp client: %w", err)
+	}
+	defer sftpCli.Close()
+
+	srcFile, err := sftpCli.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %w", err)
+	}
+	defer srcFile.Close()
+
+	srcFileInfo, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat remote file: %w", err)
+	}
+
+	dstFile, err := sftpCli.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %w", err)
+	}
+	defer dstFile.Close()
+
+	// 写入内容前即设置权限，以免如私钥等文件的副本在复制后、修改权限前被其他用户读取
+	if err := dstFile.Chmod(srcFileInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to change remote file mode: %w", err)
+	}
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return fmt.Errorf("failed to copy remote file: %w", err)
+	}
+
+	if srcStat, ok := srcFileInfo.Sys().(*sftp.FileStat); ok {
+		if err := c.chownFileByID(useSCP, dstPath, int(srcStat.UID), int(srcStat.GID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 检查远程服务器上指定路径的文件是否存在。
+//
+// 入参:
+//   - useSCP: 是否使用 SCP 而非 SFTP。
+//   - path: 远程文件路径。
+//
+// 出参:
+//   - 是否存在。
+//   - 错误。
+func (c *Client) FileExists(useSCP bool, path string) (bool, error) {
+	if useSCP {
+		// SCP 协议不支持查询文件信息，回退为执行 Shell 命令
+		_, stderr, err := c.ExecCommand(fmt.Sprintf("test -e %s", quoteShellArg(path)))
+		if err != nil {
+			var exitErr *ssh.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitStatus() == 1 {
+				return false, nil
+			}
+
+			return false, fmt.Errorf("failed to stat remote file (stderr: %s): %w", stderr, err)
+		}
+
+		return true, nil
+	}
+
+	sftpCli, err := sftp.NewClient(c.Client)
+	if err != nil {
+		return false, fmt.Errorf("failed to create sftp client: %w", err)
+	}
+	defer sftpCli.Close()
+
+	if _, err := sftpCli.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to stat remote file: %w", err)
+	}
+
+	return true, nil
+}
+
+// 修改远程服务器上文件的权限。
+//
+// 入参:
+//   - useSCP: 是否使用 SCP 而非 SFTP。
+//   - path: 远程文件路径。
+//   - mode: 文件权限。
+//
+// 出参:
+//   - 错误。
+func (c *Client) ChmodFile(useSCP bool, path string, mode os.FileMode) error {
+	if useSCP {
+		// SCP 协议不支持修改文件权限，回退为执行 Shell 命令
+		if _, stderr, err := c.ExecCommand(fmt.Sprintf("chmod %04o %s", mode.Perm(), quoteShellArg(path))); err != nil {
+			return fmt.Errorf("failed to change remote file mode (stderr: %s): %w", stderr, err)
+		}
+
+		return nil
+	}
+
+	sftpCli, err := sftp.NewClient(c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create sftp client: %w", err)
+	}
+	defer sftpCli.Close()
+
+	if err := sftpCli.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to change remote file mode: %w", err)
+	}
+
+	return nil
+}
+
+// 修改远程服务器上文件的所有者和所属组。
+// 由于 SFTP 协议仅支持数字形式的用户和组 ID，因此总是通过执行 Shell 命令实现。
+//
+// 入参:
+//   - path: 远程文件路径。
+//   - owner: 所有者用户名或用户 ID。为空时不修改。
+//   - group: 所属组名或组 ID。为空时不修改。
+//
+// 出参:
+//   - 错误。
+func (c *Client) ChownFile(path string, owner string, group string) error {
+	if owner == "" && group == "" {
+		return nil
+	}
+
+	spec := owner
+	if group != "" {
+		spec = owner + ":" + group
+	}
+
+	if _, stderr, err := c.ExecCommand(fmt.Sprintf("chown %s %s", quoteShellArg(spec), quoteShellArg(path))); err != nil {
+		return fmt.Errorf("failed to change remote file owner (stderr: %s): %w", stderr, err)
+	}
+
+	return nil
+}
+
+// 表示远程文件的权限、所有者和所属组。
+type remoteFileAttrs struct {
+	Mode os.FileMode
+	Uid  int
+	Gid  int
+}
+
+// 获取远程文件的权限、所有者和所属组。文件不存在时返回 nil。
+func (c *Client) statFileAttrs(useSCP bool, path string) (*remoteFileAttrs, error) {
+	if useSCP {
+		if exists, err := c.FileExists(useSCP, path); err != nil {
+			return nil, err
+		} else if !exists {
+			return nil, nil
+		}
+
+		// SCP 协议不支持查询文件信息，回退为执行 Shell 命令；依次兼容 GNU 与 BSD 的 stat 命令
+		quotedPath := quoteShellArg(path)
+		stdout, stderr, err := c.ExecCommand(fmt.Sprintf("stat -c '%%a %%u %%g' %s 2>/dev/null || stat -f '%%Lp %%u %%g' %s", quotedPath, quotedPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat remote file (stderr: %s): %w", stderr, err)
+		}
+
+		fields := strings.Fields(stdout)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("failed to stat remote file: unexpected output '%s'", strings.TrimSpace(stdout))
+		}
+
+		mode, err := strconv.ParseUint(fields[0], 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat remote file: unexpected mode '%s'", fields[0])
+		}
+		uid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat remote file: unexpected uid '%s'", fields[1])
+		}
+		gid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat remote file: unexpected gid '%s'", fields[2])
+		}
+
+		return &remoteFileAttrs{Mode: os.FileMode(mode).Perm(), Uid: uid, Gid: gid}, nil
+	}
+
+	sftpCli, err := sftp.NewClient(c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sftp client: %w", err)
+	}
+	defer sftpCli.Close()
+
+	fileInfo, err := sftpCli.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to stat remote file: %w", err)
+	}
+
+	attrs := &remoteFileAttrs{Mode: fileInfo.Mode().Perm(), Uid: -1, Gid: -1}
+	if stat, ok := fileInfo.Sys().(*sftp.FileStat); ok {
+		attrs.Uid = int(stat.UID)
+		attrs.Gid = int(stat.GID)
+	}
+
+	return attrs, nil
+}
+
+// 按用户和组 ID 修改远程文件的所有者和所属组。ID 为负数时不修改。
+func (c *Client) chownFileByID(useSCP bool, path string, uid int, gid int) error {
+	if uid < 0 || gid < 0 {
+		return nil
+	}
+
+	if useSCP {
+		// SCP 协议不支持修改文件所有者，回退为执行 Shell 命令
+		if _, stderr, err := c.ExecCommand(fmt.Sprintf("chown %d:%d %s", uid, gid, quoteShellArg(path))); err != nil {
+			return fmt.Errorf("failed to change remote file owner (stderr: %s): %w", stderr, err)
+		}
+
+		return nil
+	}
+
+	sftpCli, err := sftp.NewClient(c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create sftp client: %w", err)
+	}
+	defer sftpCli.Close()
+
+	// 所有者和所属组已一致时无需修改，以免非特权用户因无权执行 chown 而失败
+	if fileInfo, err := sftpCli.Stat(path); err == nil {
+		if stat, ok := fileInfo.Sys().(*sftp.FileStat); ok && int(stat.UID) == uid && int(stat.GID) == gid {
+			return nil
+		}
+	}
+
+	if err := sftpCli.Chown(path, uid, gid); err != nil {
+		return fmt.Errorf("failed to change remote file owner: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) writeFileWithSCP(path string, data []byte) error {
+	return c.writeFileWithSCPPerm(path, data, 0)
+}
+
+func (c *Client) writeFileWithSCPPerm(path string, data []byte, perm os.FileMode) error {
+	scpCli, err := scp.NewClientFromExistingSSH(c.Client, &scp.ClientOption{})
+	if err != nil {
+		return fmt.Errorf("failed to create scp client: %w", err)
+	}
+
+	reader := bytes.NewReader(data)
+	err = scpCli.CopyToRemote(reader, path, &scp.FileTransferOption{Perm: perm})
+	if err != nil {
+		return fmt.Errorf("failed to write to remote file: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) writeFileWithSFTP(path string, data []byte) error {
+	return c.writeFileWithSFTPPerm(path, data, 0)
+}
+
+func (c *Client) writeFileWithSFTPPerm(path string, data []byte, perm os.FileMode) error {
+	sftpCli, err := sftp.NewClient(c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create sftp client: %w", err)
+	}
+	defer sftpCli.Close()
+
+	if err := sftpCli.MkdirAll(filepath.ToSlash(filepath.Dir(path))); err != nil {
+		return fmt.Errorf("failed to create remote directory: %w", err)
+	}
+
+	file, err := sftpCli.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %w", err)
+	}
+	defer file.Close()
+
+	if perm != 0 {
+		if err := file.Chmod(perm); err != nil {
+			return fmt.Errorf("failed to change remote file mode: %w", err)
+		}
+	}
+
+	_, err = file.Write(data)
+	if err != nil {
+		return fmt.Errorf("failed to write to remote file: %w", err)
+	}
+
+	return nil
+}
+
+// 会话被关闭后远程输出可能仍在写入，因此需要并发安全的缓冲区。
+type syncBuffer struct {
+	buf bytes.Buffer
+	mtx sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.String()
+}
+
+func quoteShellArg(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func (c ServerConfig) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	mode := c.HostKeyVerifyMode
+	if mode == "" {
+		mode = HOST_KEY_VERIFY_MODE_TOFU
+	}
+
+	switch mode {
+	case HOST_KEY_VERIFY_MODE_NONE:
+		return ssh.InsecureIgnoreHostKey(), nil
+
+	case HOST_KEY_VERIFY_MODE_TOFU, HOST_KEY_VERIFY_MODE_STRICT:
+		break
+
+	default:
+		return nil, fmt.Errorf("unsupported host key verify mode '%s'", mode)
+	}
+
+	var knownHostsCallback ssh.HostKeyCallback
+	if strings.TrimSpace(c.KnownHosts) != "" {
+		callback, err := newKnownHostsCallback(c.KnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse known_hosts: %w", err)
+		}
+		knownHostsCallback = callback
+	}
+
+	fingerprints := make([]string, 0, len(c.HostKeyFingerprints))
+	for _, fingerprint := range c.HostKeyFingerprints {
+		if fingerprint = strings.TrimSpace(fingerprint); fingerprint != "" {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+		if slices.Contains(fingerprints, fingerprint) {
+			return nil
+		}
+
+		known := len(fingerprints) > 0
+		if knownHostsCallback != nil {
+			err := knownHostsCallback(hostname, remote, key)
+			if err == nil {
+				return nil
+			}
+
+			var keyErr *knownhosts.KeyError
+			if !errors.As(err, &keyErr) {
+				return err
+			}
+			known = known || len(keyErr.Want) > 0
+		}
+
+		if known {
+			return &HostKeyError{Addr: hostname, Fingerprint: fingerprint, Mismatch: true}
+		}
+
+		// 无法持久化主机公钥时，若仍信任首次连接的公钥，则后续连接将始终不校验，因此按严格模式处理
+		if mode == HOST_KEY_VERIFY_MODE_STRICT || c.OnHostKeyTrusted == nil {
+			return &HostKeyError{Addr: hostname, Fingerprint: fingerprint}
+		}
+
+		// 首次连接，信任主机公钥
+		if err := c.OnHostKeyTrusted(fingerprint); err != nil {
+			return fmt.Errorf("failed to trust ssh host key: %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
+func newKnownHostsCallback(content string) (ssh.HostKeyCallback, error) {
+	// knownhosts 包仅支持从文件中读取，读取完成后即可删除临时文件
+	file, err := os.CreateTemp("", "certimate-known-hosts-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	return knownhosts.New(file.Name())
+}
+
+func (c ServerConfig) addr() string {
+	host := c.Host
+	if host == "" {
+		host = "localhost"
+	}
+
+	port := c.Port
+	if port == 0 {
+		port = 22
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
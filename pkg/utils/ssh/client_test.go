@@ -0,0 +1,281 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newTestHostKey(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, privkey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(privkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signer
+}
+
+// 启动一个仅完成握手的 SSH 服务器，返回其主机与端口。
+func startTestServer(t *testing.T, hostKey ssh.Signer) (string, int32) {
+	t.Helper()
+
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				defer sshConn.Close()
+
+				go ssh.DiscardRequests(reqs)
+				for newChan := range chans {
+					newChan.Reject(ssh.Prohibited, "not supported")
+				}
+			}()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return host, int32(portNum)
+}
+
+func connectTestServer(t *testing.T, server ServerConfig) error {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", server.addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client, err := NewClientConn(conn, server)
+	if err != nil {
+		return err
+	}
+
+	return client.Close()
+}
+
+func TestHostKeyVerify(t *testing.T) {
+	hostKey := newTestHostKey(t)
+	otherKey := newTestHostKey(t)
+	host, port := startTestServer(t, hostKey)
+
+	fingerprint := ssh.FingerprintSHA256(hostKey.PublicKey())
+	otherFingerprint := ssh.FingerprintSHA256(otherKey.PublicKey())
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	knownHostsLine := knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey.PublicKey())
+	otherKnownHostsLine := knownhosts.Line([]string{knownhosts.Normalize(addr)}, otherKey.PublicKey())
+	otherHostKnownHostsLine := knownhosts.Line([]string{knownhosts.Normalize("192.0.2.1:22")}, otherKey.PublicKey())
+
+	tests := []struct {
+		name         string
+		mode         string
+		fingerprints []string
+		knownHosts   string
+		onTrusted    bool
+		onTrustedErr error
+		wantErr      bool
+		wantMismatch bool
+		wantTrusted  string
+	}{
+		{
+			name: "none accepts any host key",
+			mode: HOST_KEY_VERIFY_MODE_NONE,
+		},
+		{
+			name:    "strict rejects unknown host key",
+			mode:    HOST_KEY_VERIFY_MODE_STRICT,
+			wantErr: true,
+		},
+		{
+			name:         "strict accepts pinned fingerprint",
+			mode:         HOST_KEY_VERIFY_MODE_STRICT,
+			fingerprints: []string{otherFingerprint, fingerprint},
+		},
+		{
+			name:         "strict rejects mismatched fingerprint",
+			mode:         HOST_KEY_VERIFY_MODE_STRICT,
+			fingerprints: []string{otherFingerprint},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name:       "strict accepts known_hosts",
+			mode:       HOST_KEY_VERIFY_MODE_STRICT,
+			knownHosts: knownHostsLine,
+		},
+		{
+			name:         "strict rejects mismatched known_hosts",
+			mode:         HOST_KEY_VERIFY_MODE_STRICT,
+			knownHosts:   otherKnownHostsLine,
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name:        "tofu trusts unknown host key",
+			mode:        HOST_KEY_VERIFY_MODE_TOFU,
+			onTrusted:   true,
+			wantTrusted: fingerprint,
+		},
+		{
+			name:        "tofu is the default mode",
+			mode:        "",
+			onTrusted:   true,
+			wantTrusted: fingerprint,
+		},
+		{
+			name:        "tofu trusts host missing from known_hosts",
+			mode:        HOST_KEY_VERIFY_MODE_TOFU,
+			knownHosts:  otherHostKnownHostsLine,
+			onTrusted:   true,
+			wantTrusted: fingerprint,
+		},
+		{
+			name:    "tofu without callback falls back to strict",
+			mode:    HOST_KEY_VERIFY_MODE_TOFU,
+			wantErr: true,
+		},
+		{
+			name:         "tofu fails when callback fails",
+			mode:         HOST_KEY_VERIFY_MODE_TOFU,
+			onTrusted:    true,
+			onTrustedErr: errors.New("save failed"),
+			wantErr:      true,
+		},
+		{
+			name:         "tofu accepts pinned fingerprint",
+			mode:         HOST_KEY_VERIFY_MODE_TOFU,
+			fingerprints: []string{fingerprint},
+			onTrusted:    true,
+		},
+		{
+			name:         "tofu rejects mismatched fingerprint",
+			mode:         HOST_KEY_VERIFY_MODE_TOFU,
+			fingerprints: []string{otherFingerprint},
+			onTrusted:    true,
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name:         "tofu rejects mismatched known_hosts",
+			mode:         HOST_KEY_VERIFY_MODE_TOFU,
+			knownHosts:   otherKnownHostsLine,
+			onTrusted:    true,
+			wantErr:      true,
+			wantMismatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trusted string
+			server := ServerConfig{
+				Host:                host,
+				Port:                port,
+				AuthMethod:          AUTH_METHOD_NONE,
+				HostKeyVerifyMode:   tt.mode,
+				HostKeyFingerprints: tt.fingerprints,
+				KnownHosts:          tt.knownHosts,
+			}
+			if tt.onTrusted {
+				server.OnHostKeyTrusted = func(fingerprint string) error {
+					trusted = fingerprint
+					return tt.onTrustedErr
+				}
+			}
+
+			err := connectTestServer(t, server)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClientConn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && tt.onTrustedErr == nil {
+				var hostKeyErr *HostKeyError
+				if !errors.As(err, &hostKeyErr) {
+					t.Fatalf("NewClientConn() error = %v, want a HostKeyError", err)
+				}
+				if hostKeyErr.Mismatch != tt.wantMismatch {
+					t.Errorf("HostKeyError.Mismatch = %v, want %v", hostKeyErr.Mismatch, tt.wantMismatch)
+				}
+				if hostKeyErr.Fingerprint != fingerprint {
+					t.Errorf("HostKeyError.Fingerprint = %s, want %s", hostKeyErr.Fingerprint, fingerprint)
+				}
+			}
+
+			if trusted != tt.wantTrusted && tt.onTrustedErr == nil {
+				t.Errorf("trusted fingerprint = '%s', want '%s'", trusted, tt.wantTrusted)
+			}
+		})
+	}
+}
+
+func TestHandshakeTimeout(t *testing.T) {
+	// 仅接受连接而不响应握手的服务器
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+
+	startedAt := time.Now()
+	err = connectTestServer(t, ServerConfig{
+		Host:              host,
+		Port:              int32(portNum),
+		AuthMethod:        AUTH_METHOD_NONE,
+		HostKeyVerifyMode: HOST_KEY_VERIFY_MODE_NONE,
+		HandshakeTimeout:  200 * time.Millisecond,
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("NewClientConn() error = %v, want a handshake timeout", err)
+	}
+	if elapsed := time.Since(startedAt); elapsed > 5*time.Second {
+		t.Errorf("NewClientConn() returned after %s, want about 200ms", elapsed)
+	}
+}